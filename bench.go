@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+type benchStats struct {
+	mu       sync.Mutex
+	duracoes []time.Duration
+	falhas   int
+}
+
+func (s *benchStats) add(d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duracoes = append(s.duracoes, d)
+	if err != nil {
+		s.falhas++
+	}
+}
+
+func (s *benchStats) report(out *log.Logger, total time.Duration, m *client.Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.duracoes)
+	if n == 0 {
+		out.Println(i18n.T("Nenhuma sequência executada."))
+		return
+	}
+	ordenadas := slices.Clone(s.duracoes)
+	slices.Sort(ordenadas)
+
+	var soma time.Duration
+	for _, d := range ordenadas {
+		soma += d
+	}
+	pct := func(p float64) time.Duration {
+		return ordenadas[int(float64(n-1)*p)]
+	}
+
+	out.Printf(i18n.T("Sequências: %d | Falhas: %d | Tempo total: %v | Vazão: %.2f seq/s"),
+		n, s.falhas, total.Round(time.Millisecond), float64(n)/total.Seconds())
+	out.Printf(i18n.T("Latência: min=%v média=%v p50=%v p95=%v max=%v"),
+		ordenadas[0], soma/time.Duration(n), pct(0.5), pct(0.95), ordenadas[n-1])
+	enviados, recebidos := m.Bytes()
+	out.Printf(i18n.T("Bytes por sequência: enviados=%d recebidos=%d"),
+		enviados/int64(n), recebidos/int64(n))
+}
+
+func startMetricsServer(out *log.Logger, addr string, m *client.Metrics) {
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			out.Printf(i18n.T("Servidor de métricas encerrado: %v"), err)
+		}
+	}()
+	out.Printf(i18n.T("Métricas disponíveis em http://%s/metrics"), addr)
+}
+
+func newInstrumentedClient(server *serverFlags, proto string, m *client.Metrics, interceptors []client.Interceptor) (client.Client, error) {
+	c, err := newClient(proto)
+	if err != nil {
+		return nil, err
+	}
+	server.configure(c, proto)
+	if mc, ok := c.(interface{ SetMetrics(client.MetricsHook) }); ok {
+		mc.SetMetrics(m)
+	}
+	if len(interceptors) > 0 {
+		c = client.WithInterceptors(c, interceptors...)
+	}
+	return c, nil
+}
+
+// limitFlags são as opções comuns de proteção do servidor nos modos de carga:
+// circuit breaker, vazão máxima e chamadas simultâneas.
+type limitFlags struct {
+	breaker    *bool
+	rate       *float64
+	burst      *int
+	rateOp     *string
+	inflight   *int
+	inflightOp *string
+}
+
+func addLimitFlags(fs *flag.FlagSet) *limitFlags {
+	return &limitFlags{
+		breaker:    fs.Bool("breaker", false, i18n.T("Interrompe as chamadas de operações com muitas falhas (circuit breaker)")),
+		rate:       fs.Float64("rate", 0, i18n.T("Máximo de requisições por segundo somando todos os clientes (0 = sem limite)")),
+		burst:      fs.Int("burst", 1, i18n.T("Rajada máxima permitida pelo limite de vazão")),
+		rateOp:     fs.String("rate-op", "", i18n.T("Limite de requisições por segundo por operação (ex.: echo=2,soma=5)")),
+		inflight:   fs.Int("max-inflight", 0, i18n.T("Máximo de requisições simultâneas (0 = sem limite)")),
+		inflightOp: fs.String("max-inflight-op", "", i18n.T("Máximo de requisições simultâneas por operação (ex.: historico=1)")),
+	}
+}
+
+// interceptors monta a cadeia compartilhada por todos os clientes: o circuit
+// breaker vem antes do limitador para que chamadas recusadas não consumam a
+// vazão disponível.
+func (f *limitFlags) interceptors(out *log.Logger, m *client.Metrics) ([]client.Interceptor, error) {
+	var chain []client.Interceptor
+	if *f.breaker {
+		cb := client.NewCircuitBreaker()
+		cb.OnStateChange = func(op string, estado client.CircuitState) {
+			out.Printf(i18n.T("Circuit breaker: operação '%s' agora está %s"), op, estado)
+			m.ObserveCircuitState(op, estado)
+		}
+		chain = append(chain, cb.Interceptor())
+	}
+
+	rateOp := parseKeyValues(*f.rateOp)
+	inflightOp := parseKeyValues(*f.inflightOp)
+	if *f.rate <= 0 && *f.inflight <= 0 && len(rateOp) == 0 && len(inflightOp) == 0 {
+		return chain, nil
+	}
+
+	l := client.NewRateLimiter()
+	l.SetRate(*f.rate, *f.burst)
+	l.SetMaxInFlight(*f.inflight)
+	for op, v := range rateOp {
+		taxa, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("limite de vazão inválido para '%s': %s"), op, v)
+		}
+		l.SetOperationRate(op, taxa, *f.burst)
+	}
+	for op, v := range inflightOp {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("limite de simultaneidade inválido para '%s': %s"), op, v)
+		}
+		l.SetOperationMaxInFlight(op, n)
+	}
+	return append(chain, l.Interceptor()), nil
+}
+
+// quietLogs silencia o logger padrão (usado pela sequência de testes e pelos
+// clientes) e devolve um logger separado para o resumo da execução.
+func quietLogs(verbose bool) *log.Logger {
+	out := log.New(os.Stderr, "", log.LstdFlags)
+	if !verbose {
+		log.SetOutput(io.Discard)
+	}
+	return out
+}
+
+func runBenchCommand(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo a ser usado"))
+	server := addServerFlags(fs, i18n.T("IP do servidor (padrão: o do perfil de configuração ou do -registry)"))
+	n := fs.Int("n", 10, i18n.T("Número total de sequências de teste"))
+	workers := fs.Int("c", 1, i18n.T("Número de clientes concorrentes"))
+	timeout := fs.Duration("timeout", 60*time.Second, i18n.T("Timeout de cada sequência"))
+	metricsAddr := fs.String("metrics", ":9091", i18n.T("Endereço do endpoint /metrics (vazio desativa)"))
+	verbose := fs.Bool("v", false, i18n.T("Exibe os logs de cada sequência"))
+	limits := addLimitFlags(fs)
+	fs.Parse(args)
+
+	if err := server.setup(fs); err != nil {
+		log.Fatal(err)
+	}
+
+	out := quietLogs(*verbose)
+	m := client.NewMetrics()
+	startMetricsServer(out, *metricsAddr, m)
+	chain, err := limits.interceptors(out, m)
+	if err != nil {
+		out.Fatal(err)
+	}
+
+	jobs := make(chan int)
+	go func() {
+		for i := 0; i < *n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	var stats benchStats
+	var wg sync.WaitGroup
+	inicio := time.Now()
+	for w := 0; w < *workers; w++ {
+		c, err := newInstrumentedClient(server, *proto, m, chain)
+		if err != nil {
+			out.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+				t0 := time.Now()
+				_, err := runTestSequence(ctx, c, *server.host, *server.id, *proto)
+				cancel()
+				stats.add(time.Since(t0), err)
+				if err != nil {
+					out.Printf(i18n.T("Sequência falhou: %v"), err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	out.Printf("--- BENCH %s ---", *proto)
+	stats.report(out, time.Since(inicio), m)
+	if stats.falhas > 0 {
+		os.Exit(1)
+	}
+}
+
+func runSoakCommand(args []string) {
+	fs := flag.NewFlagSet("soak", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo a ser usado"))
+	server := addServerFlags(fs, i18n.T("IP do servidor (padrão: o do perfil de configuração ou do -registry)"))
+	duracao := fs.Duration("duracao", 10*time.Minute, i18n.T("Duração total do teste de resistência"))
+	intervalo := fs.Duration("intervalo", time.Second, i18n.T("Intervalo entre sequências"))
+	timeout := fs.Duration("timeout", 60*time.Second, i18n.T("Timeout de cada sequência"))
+	metricsAddr := fs.String("metrics", ":9091", i18n.T("Endereço do endpoint /metrics (vazio desativa)"))
+	verbose := fs.Bool("v", false, i18n.T("Exibe os logs de cada sequência"))
+	limits := addLimitFlags(fs)
+	fs.Parse(args)
+
+	if err := server.setup(fs); err != nil {
+		log.Fatal(err)
+	}
+
+	out := quietLogs(*verbose)
+	m := client.NewMetrics()
+	startMetricsServer(out, *metricsAddr, m)
+	chain, err := limits.interceptors(out, m)
+	if err != nil {
+		out.Fatal(err)
+	}
+
+	c, err := newInstrumentedClient(server, *proto, m, chain)
+	if err != nil {
+		out.Fatal(err)
+	}
+
+	var stats benchStats
+	inicio := time.Now()
+	fim := inicio.Add(*duracao)
+	for time.Now().Before(fim) {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		t0 := time.Now()
+		_, err := runTestSequence(ctx, c, *server.host, *server.id, *proto)
+		cancel()
+		stats.add(time.Since(t0), err)
+		if err != nil {
+			out.Printf(i18n.T("Sequência falhou: %v"), err)
+		}
+		time.Sleep(*intervalo)
+	}
+
+	out.Printf("--- SOAK %s ---", *proto)
+	stats.report(out, time.Since(inicio), m)
+}
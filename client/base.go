@@ -2,25 +2,105 @@ package client
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"errors"
 	"net"
 	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type baseClient struct {
-	conn net.Conn
+	conn     net.Conn
+	counter  *countingConn
+	location *time.Location
+	metrics  MetricsHook
+	protocol string
+	tracer   trace.Tracer
+	recorder *wire.Recorder
+	resolver Resolver
+	tls      *tls.Config
+
+	maxFrameSize int64
+	compressao   []string
+	codificacao  string
 }
 
-func (c *baseClient) Connect(ctx context.Context, host, port string) error {
-	var d net.Dialer
-	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+func (c *baseClient) SetDisplayLocation(loc *time.Location) {
+	c.location = loc
+}
+
+// SetTLS faz as próximas conexões usarem TLS com cfg; sem ServerName, o
+// certificado é verificado contra o host de cada servidor.
+func (c *baseClient) SetTLS(cfg *tls.Config) {
+	c.tls = cfg
+}
+
+func (c *baseClient) SetMetrics(m MetricsHook) {
+	c.metrics = m
+}
+
+// SetCapture grava todas as mensagens trocadas nas próximas conexões.
+func (c *baseClient) SetCapture(r *wire.Recorder) {
+	c.recorder = r
+}
+
+func (c *baseClient) Connect(ctx context.Context, host, port string) (err error) {
+	ctx, end := c.startSpan(ctx, "Connect", "connect")
+	defer func() { end(err) }()
+
+	endpoints, err := c.endpoints(ctx, host, port)
 	if err != nil {
-		return fmt.Errorf("falha ao conectar (%s:%s): %w", host, port, err)
+		return newError(ErrResolve, err, "falha ao resolver servidor '%s'", host)
+	}
+
+	var d net.Dialer
+	var conn net.Conn
+	var errs []error
+	for _, e := range endpoints {
+		conn, err = c.dial(ctx, &d, e)
+		if err == nil {
+			break
+		}
+		errs = append(errs, newError(ErrConnect, err, "falha ao conectar (%s:%s)", e.Host, e.Port))
+	}
+	if conn == nil {
+		return errors.Join(errs...)
+	}
+	if c.recorder != nil {
+		wrapped, err := c.recorder.Wrap(conn, c.protocol)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		conn = wrapped
 	}
-	c.conn = conn
+	c.counter = &countingConn{Conn: conn}
+	c.conn = c.counter
+	c.codificacao = ""
 	return nil
 }
 
+// dial abre a conexão TCP com e e, se SetTLS foi usado, faz o handshake TLS.
+func (c *baseClient) dial(ctx context.Context, d *net.Dialer, e Endpoint) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(e.Host, e.Port))
+	if err != nil || c.tls == nil {
+		return conn, err
+	}
+	cfg := c.tls
+	if cfg.ServerName == "" {
+		cfg = cfg.Clone()
+		cfg.ServerName = e.Host
+	}
+	tc := tls.Client(conn, cfg)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, newError(ErrConnect, err, "falha no handshake TLS")
+	}
+	return tc, nil
+}
+
 func (c *baseClient) Disconnect() error {
 	if c.conn != nil {
 		return c.conn.Close()
@@ -35,3 +115,29 @@ func (c *baseClient) setDeadline(ctx context.Context) error {
 	}
 	return c.conn.SetDeadline(deadline)
 }
+
+// track inicia a medição de uma troca com o servidor; a função retornada deve
+// ser chamada ao fim de sendAndReceive com o tipo do erro (vazio se sucesso).
+func (c *baseClient) track(ctx context.Context, op string) func(tipoErro string) {
+	if c.counter == nil {
+		return func(string) {}
+	}
+	inicio := time.Now()
+	env, rec := c.counter.enviados.Load(), c.counter.recebidos.Load()
+	return func(tipoErro string) {
+		enviados := c.counter.enviados.Load() - env
+		recebidos := c.counter.recebidos.Load() - rec
+		recordPayload(ctx, enviados, recebidos)
+		if c.metrics == nil {
+			return
+		}
+		c.metrics.ObserveExchange(Exchange{
+			Protocolo:      c.protocol,
+			Operacao:       op,
+			Duracao:        time.Since(inicio),
+			BytesEnviados:  enviados,
+			BytesRecebidos: recebidos,
+			Erro:           tipoErro,
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "fechado"
+	case CircuitOpen:
+		return "aberto"
+	case CircuitHalfOpen:
+		return "semi-aberto"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+type CircuitOpenError struct {
+	Operacao string
+	Ate      time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return i18n.T("circuito aberto para a operação '%s' até %s", e.Operacao, e.Ate.Format("15:04:05"))
+}
+
+// CircuitBreaker acompanha a taxa de falhas de cada operação numa janela das
+// últimas chamadas. Quando a taxa passa do limite o circuito abre e as chamadas
+// falham de imediato com *CircuitOpenError; depois da espera ele fica
+// semi-aberto e um OpStatus (ou a própria chamada, se não houver token) decide
+// se volta a fechar.
+type CircuitBreaker struct {
+	Janela       int
+	MinAmostras  int
+	LimiteFalhas float64
+	Espera       time.Duration
+
+	// Falha decide quais erros contam para o circuito. Por padrão contam
+	// timeouts, falhas de conexão e recusas por sobrecarga (IsThrottled); os
+	// demais erros do servidor são respostas normais a requisições inválidas.
+	Falha func(error) bool
+
+	// OnStateChange, se definido, é chamado a cada mudança de estado, fora
+	// do lock do CircuitBreaker: o callback pode consultar State ou fazer
+	// chamadas pelo próprio cliente.
+	OnStateChange func(op string, estado CircuitState)
+
+	mu         sync.Mutex
+	circuitos  map[string]*circuit
+	transicoes []transicao
+}
+
+type transicao struct {
+	op     string
+	estado CircuitState
+}
+
+type circuit struct {
+	estado     CircuitState
+	resultados []bool
+	pos        int
+	abertoAte  time.Time
+}
+
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		Janela:       20,
+		MinAmostras:  5,
+		LimiteFalhas: 0.5,
+		Espera:       30 * time.Second,
+		circuitos:    make(map[string]*circuit),
+	}
+}
+
+func WithCircuitBreaker(c Client, cb *CircuitBreaker) *InterceptedClient {
+	return WithInterceptors(c, cb.Interceptor())
+}
+
+func defaultBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch errorKind(err) {
+	case "timeout", "conexao":
+		return true
+	}
+	return IsThrottled(err)
+}
+
+func (cb *CircuitBreaker) State(op string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.circuitos[op]; ok {
+		return c.estado
+	}
+	return CircuitClosed
+}
+
+func (cb *CircuitBreaker) States() map[string]CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	out := make(map[string]CircuitState, len(cb.circuitos))
+	for op, c := range cb.circuitos {
+		out[op] = c.estado
+	}
+	return out
+}
+
+func (cb *CircuitBreaker) circuitLocked(op string) *circuit {
+	c, ok := cb.circuitos[op]
+	if !ok {
+		c = &circuit{resultados: make([]bool, 0, max(cb.Janela, 1))}
+		cb.circuitos[op] = c
+	}
+	return c
+}
+
+func (cb *CircuitBreaker) setStateLocked(op string, c *circuit, estado CircuitState) {
+	if c.estado == estado {
+		return
+	}
+	c.estado = estado
+	if estado == CircuitOpen {
+		c.abertoAte = time.Now().Add(cb.Espera)
+	}
+	if estado == CircuitClosed {
+		c.resultados = c.resultados[:0]
+		c.pos = 0
+	}
+	if cb.OnStateChange != nil {
+		cb.transicoes = append(cb.transicoes, transicao{op, estado})
+	}
+}
+
+// unlock libera cb.mu e só então avisa OnStateChange das transições
+// registradas enquanto o lock estava com o chamador.
+func (cb *CircuitBreaker) unlock() {
+	transicoes := cb.transicoes
+	cb.transicoes = nil
+	cb.mu.Unlock()
+	for _, t := range transicoes {
+		cb.OnStateChange(t.op, t.estado)
+	}
+}
+
+func (cb *CircuitBreaker) recordLocked(op string, c *circuit, falhou bool) {
+	janela := max(cb.Janela, 1)
+	if len(c.resultados) < janela {
+		c.resultados = append(c.resultados, falhou)
+	} else {
+		c.resultados[c.pos] = falhou
+		c.pos = (c.pos + 1) % janela
+	}
+
+	if len(c.resultados) < cb.MinAmostras {
+		return
+	}
+	falhas := 0
+	for _, f := range c.resultados {
+		if f {
+			falhas++
+		}
+	}
+	if float64(falhas)/float64(len(c.resultados)) >= cb.LimiteFalhas {
+		cb.setStateLocked(op, c, CircuitOpen)
+	}
+}
+
+// admit decide se a chamada pode seguir. probe indica que o circuito está
+// semi-aberto e esta chamada é a tentativa de recuperação.
+func (cb *CircuitBreaker) admit(op string) (probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	c := cb.circuitLocked(op)
+	switch c.estado {
+	case CircuitOpen:
+		if time.Now().Before(c.abertoAte) {
+			return false, &CircuitOpenError{Operacao: op, Ate: c.abertoAte}
+		}
+		cb.setStateLocked(op, c, CircuitHalfOpen)
+		return true, nil
+	case CircuitHalfOpen:
+		// Já há uma tentativa de recuperação em andamento.
+		return false, &CircuitOpenError{Operacao: op, Ate: c.abertoAte}
+	}
+	return false, nil
+}
+
+func (cb *CircuitBreaker) finishProbe(op string, ok bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	c := cb.circuitLocked(op)
+	if ok {
+		cb.setStateLocked(op, c, CircuitClosed)
+		return
+	}
+	cb.setStateLocked(op, c, CircuitOpen)
+}
+
+func requestToken(req any) string {
+	switch r := req.(type) {
+	case EchoRequest:
+		return r.Token
+	case SomaRequest:
+		return r.Token
+	case TimestampRequest:
+		return r.Token
+	case StatusRequest:
+		return r.Token
+	case HistoricoRequest:
+		return r.Token
+	case InfoRequest:
+		return r.Token
+	case LogoutRequest:
+		return r.Token
+	}
+	return ""
+}
+
+func (cb *CircuitBreaker) Interceptor() Interceptor {
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		if op == OpDisconnect {
+			return next(ctx, op, req)
+		}
+		falha := cb.Falha
+		if falha == nil {
+			falha = defaultBreakerFailure
+		}
+
+		probe, err := cb.admit(op)
+		if err != nil {
+			return nil, err
+		}
+		if probe {
+			if token := requestToken(req); token != "" && op != OpStatus {
+				_, perr := next(ctx, OpStatus, StatusRequest{Token: token})
+				if falha(perr) {
+					cb.finishProbe(op, false)
+					return nil, fmt.Errorf("%s: %w", i18n.T("circuito '%s' continua aberto: sonda OpStatus falhou", op), perr)
+				}
+				cb.finishProbe(op, true)
+				probe = false
+			}
+		}
+
+		res, err := next(ctx, op, req)
+		falhou := falha(err)
+		if probe {
+			cb.finishProbe(op, !falhou)
+			return res, err
+		}
+
+		cb.mu.Lock()
+		cb.recordLocked(op, cb.circuitLocked(op), falhou)
+		cb.unlock()
+		return res, err
+	}
+}
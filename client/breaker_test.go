@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOnStateChangeOutsideLock(t *testing.T) {
+	cb := NewCircuitBreaker()
+	cb.MinAmostras = 2
+	var estados []CircuitState
+	cb.OnStateChange = func(op string, estado CircuitState) {
+		// Com o lock ainda em uso, State travaria aqui.
+		if got := cb.State(op); got != estado {
+			t.Errorf("State(%s) = %v no callback, esperado %v", op, got, estado)
+		}
+		estados = append(estados, estado)
+	}
+	next := func(ctx context.Context, op string, req any) (any, error) {
+		return nil, io.EOF
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in := cb.Interceptor()
+		for range 2 {
+			in(context.Background(), OpEcho, EchoRequest{}, next)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStateChange travou o CircuitBreaker")
+	}
+	if len(estados) != 1 || estados[0] != CircuitOpen {
+		t.Errorf("transições = %v, esperado [aberto]", estados)
+	}
+}
+
+func TestDefaultBreakerFailure(t *testing.T) {
+	casos := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{context.DeadlineExceeded, true},
+		{&ServerError{Mensagem: "Muitas requisições, tente mais tarde"}, true},
+		{&ServerError{Mensagem: "Too many requests"}, true},
+		{&ServerError{Mensagem: "Parâmetro 'nums' inválido"}, false},
+		{&ServerError{Mensagem: "Token inválido"}, false},
+		{&FrameTooLargeError{Protocolo: "json", Tamanho: -1, Limite: 1}, false},
+	}
+	for _, c := range casos {
+		if got := defaultBreakerFailure(c.err); got != c.want {
+			t.Errorf("defaultBreakerFailure(%v) = %v, esperado %v", c.err, got, c.want)
+		}
+	}
+}
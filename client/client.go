@@ -1,6 +1,9 @@
 package client
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type AuthResponse struct {
 	Token     string
@@ -28,12 +31,17 @@ type TimestampResponse struct {
 	TimestampFormatado   string
 	Timezone             string
 	InformacoesTemporais string
+	Horario              time.Time
+	TimezoneServidor     string
+	// FusoDesconhecido indica que TimezoneServidor não é um fuso conhecido
+	// e que os horários sem fuso do servidor foram interpretados em UTC.
+	FusoDesconhecido bool
 }
 
 type StatusResponse struct {
 	Status               string
 	OperacoesProcessadas int
-	Estatisticas         map[string]any
+	Estatisticas         Estatisticas
 }
 
 type OperacaoInfo struct {
@@ -44,7 +52,20 @@ type OperacaoInfo struct {
 
 type HistoricoResponse struct {
 	Operacoes    []OperacaoInfo
-	Estatisticas map[string]any
+	Estatisticas Estatisticas
+	// ProximoCursor pede a página seguinte (mais antiga) em OpHistoricoPagina;
+	// vazio na última página ou quando o servidor não pagina o histórico.
+	ProximoCursor string
+}
+
+// Pagina seleciona uma página do histórico, que é percorrido da operação mais
+// recente para a mais antiga: até Limite operações (zero para todas), pulando
+// as Offset mais recentes ou, se Cursor estiver definido, a partir do
+// ProximoCursor da página anterior.
+type Pagina struct {
+	Limite int
+	Offset int
+	Cursor string
 }
 
 type InfoResponse struct {
@@ -70,6 +91,8 @@ type Client interface {
 
 	OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error)
 
+	OpHistoricoPagina(ctx context.Context, token string, p Pagina) (*HistoricoResponse, error)
+
 	Info(ctx context.Context, token, tipo string) (*InfoResponse, error)
 
 	Logout(ctx context.Context, token string) error
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+// Amostras com RTT acima de fatorOutlierRTT vezes a mediana são descartadas.
+const fatorOutlierRTT = 1.5
+
+type ClockSample struct {
+	Envio       time.Time
+	Recebimento time.Time
+	Servidor    time.Time
+	RTT         time.Duration
+	Offset      time.Duration
+}
+
+type ClockSyncResult struct {
+	Amostras    []ClockSample
+	Validas     []ClockSample
+	Descartadas int
+	Melhor      ClockSample
+	RTTMediano  time.Duration
+	OffsetMedio time.Duration
+	Incerteza   time.Duration
+}
+
+// ClockSync estima o desvio do relógio local em relação ao servidor (algoritmo
+// de Cristian) a partir de n trocas de OpTimestamp. O offset positivo indica
+// que o relógio do servidor está adiantado.
+func ClockSync(ctx context.Context, c Client, token string, n int, intervalo time.Duration) (*ClockSyncResult, error) {
+	if n <= 0 {
+		return nil, errors.New(i18n.T("clocksync: número de amostras deve ser positivo (recebido %d)", n))
+	}
+
+	amostras := make([]ClockSample, 0, n)
+	for i := 0; i < n; i++ {
+		if i > 0 && intervalo > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(intervalo):
+			}
+		}
+
+		s, err := clockSample(ctx, c, token)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", i18n.T("clocksync: amostra %d", i+1), err)
+		}
+		amostras = append(amostras, s)
+	}
+
+	return summarizeClockSamples(amostras), nil
+}
+
+func clockSample(ctx context.Context, c Client, token string) (ClockSample, error) {
+	envio := time.Now()
+	resp, err := c.OpTimestamp(ctx, token)
+	recebimento := time.Now()
+	if err != nil {
+		return ClockSample{}, err
+	}
+
+	servidor := resp.Horario
+	rtt := recebimento.Sub(envio)
+	meio := envio.Add(rtt / 2)
+	return ClockSample{
+		Envio:       envio,
+		Recebimento: recebimento,
+		Servidor:    servidor,
+		RTT:         rtt,
+		Offset:      servidor.Sub(meio),
+	}, nil
+}
+
+func summarizeClockSamples(amostras []ClockSample) *ClockSyncResult {
+	ordenadas := slices.Clone(amostras)
+	slices.SortFunc(ordenadas, func(a, b ClockSample) int {
+		return cmp.Compare(a.RTT, b.RTT)
+	})
+
+	mediana := ordenadas[len(ordenadas)/2].RTT
+	limite := time.Duration(float64(mediana) * fatorOutlierRTT)
+
+	var validas []ClockSample
+	var soma time.Duration
+	for _, s := range ordenadas {
+		if s.RTT > limite {
+			break
+		}
+		validas = append(validas, s)
+		soma += s.Offset
+	}
+
+	melhor := ordenadas[0]
+	return &ClockSyncResult{
+		Amostras:    amostras,
+		Validas:     validas,
+		Descartadas: len(amostras) - len(validas),
+		Melhor:      melhor,
+		RTTMediano:  mediana,
+		OffsetMedio: soma / time.Duration(len(validas)),
+		Incerteza:   melhor.RTT / 2,
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func amostrasRTT(rtts ...time.Duration) []ClockSample {
+	amostras := make([]ClockSample, len(rtts))
+	for i, rtt := range rtts {
+		// O offset identifica a amostra: 1ms para a primeira, 2ms para a segunda...
+		amostras[i] = ClockSample{RTT: rtt, Offset: time.Duration(i+1) * time.Millisecond}
+	}
+	return amostras
+}
+
+func TestSummarizeClockSamples(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		nome        string
+		amostras    []ClockSample
+		validas     int
+		mediana     time.Duration
+		melhor      time.Duration // offset da amostra de menor RTT
+		offsetMedio time.Duration
+	}{
+		{"uma amostra", amostrasRTT(30 * ms), 1, 30 * ms, 1 * ms, 1 * ms},
+		{"sem outliers", amostrasRTT(12*ms, 10*ms, 14*ms), 3, 12 * ms, 2 * ms, 2 * ms},
+		// Mediana 14ms: o limite é 21ms e a amostra de 100ms é descartada.
+		{"outlier", amostrasRTT(14*ms, 100*ms, 10*ms, 12*ms), 3, 14 * ms, 3 * ms, (1 + 3 + 4) * ms / 3},
+		// Exatamente 1,5 vez a mediana ainda é válida.
+		{"no limite", amostrasRTT(20*ms, 18*ms, 30*ms), 3, 20 * ms, 2 * ms, 2 * ms},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			res := summarizeClockSamples(tt.amostras)
+			if len(res.Validas) != tt.validas || res.Descartadas != len(tt.amostras)-tt.validas {
+				t.Errorf("válidas = %d, descartadas = %d; quer %d válidas", len(res.Validas), res.Descartadas, tt.validas)
+			}
+			if res.RTTMediano != tt.mediana {
+				t.Errorf("RTTMediano = %v, quer %v", res.RTTMediano, tt.mediana)
+			}
+			if res.Melhor.Offset != tt.melhor || res.Incerteza != res.Melhor.RTT/2 {
+				t.Errorf("Melhor = %+v, Incerteza = %v; quer offset %v", res.Melhor, res.Incerteza, tt.melhor)
+			}
+			if res.OffsetMedio != tt.offsetMedio {
+				t.Errorf("OffsetMedio = %v, quer %v", res.OffsetMedio, tt.offsetMedio)
+			}
+			if len(res.Amostras) != len(tt.amostras) {
+				t.Errorf("Amostras = %d, quer %d", len(res.Amostras), len(tt.amostras))
+			}
+		})
+	}
+}
+
+func TestClockSyncOffset(t *testing.T) {
+	quietLog(t)
+	const adiantado = time.Hour
+	// Um servidor com o relógio uma hora adiantado.
+	c := WithInterceptors(newTestClient(t, "json"), func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		res, err := next(ctx, op, req)
+		if ts, ok := res.(*TimestampResponse); ok {
+			ts.Horario = time.Now().Add(adiantado)
+		}
+		return res, err
+	})
+	ctx := context.Background()
+	auth, err := c.Auth(ctx, "520402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ClockSync(ctx, c, auth.Token, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O horário é lido depois do envio e antes do recebimento, então o erro do
+	// meio do RTT fica dentro da incerteza.
+	if d := res.Melhor.Offset - adiantado; d < -res.Incerteza || d > res.Incerteza {
+		t.Errorf("Offset = %v, quer %v ± %v", res.Melhor.Offset, adiantado, res.Incerteza)
+	}
+	if _, err := ClockSync(ctx, c, auth.Token, 0, 0); err == nil {
+		t.Error("ClockSync com 0 amostras não falhou")
+	}
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Call descreve uma chamada para o Codec: a operação (OpAuth, OpEcho...) e a
+// requisição correspondente (um dos tipos *Request de interceptor.go), junto
+// com os metadados preenchidos pelo cliente.
+type Call struct {
+	Op  string
+	Req any
+
+	// TraceContext propaga o span atual; nil sem rastreamento.
+	TraceContext map[string]string
+	// AceitaCompressao é a oferta de compressão a anunciar ao servidor (vazia
+	// depois de negociada ou sem SetCompression).
+	AceitaCompressao string
+	// Local é o fuso de exibição dos timestamps (SetDisplayLocation).
+	Local *time.Location
+	// Alternativa marca a segunda tentativa pedida por FallbackCodec.
+	Alternativa bool
+}
+
+// Codec traduz as operações do Client para as mensagens de um protocolo.
+// Encode serializa a requisição e Decode interpreta a resposta, devolvendo o
+// mesmo tipo do método correspondente do Client (*AuthResponse, *EchoResponse...;
+// nil para logout). Falhas reportadas pelo servidor devem ser *ServerError.
+type Codec interface {
+	Encode(call Call) ([]byte, error)
+	Decode(call Call, msg []byte) (any, error)
+}
+
+// FallbackCodec é implementado pelos codecs que contornam a falha de uma
+// operação. Com ok, Fallback devolve a próxima chamada a tentar ou, se next
+// for nil, a resposta a usar no lugar do erro.
+type FallbackCodec interface {
+	Codec
+	Fallback(call Call, err error) (next *Call, resp any, ok bool)
+}
+
+// Framer delimita as mensagens de um protocolo numa conexão. WriteFrame
+// comprime com alg quando o formato permite, e ReadFrame devolve a mensagem
+// já descomprimida junto com o algoritmo anunciado pelo servidor.
+type Framer interface {
+	WriteFrame(msg []byte, alg string) error
+	ReadFrame(limite int64) (msg []byte, alg string, err error)
+}
+
+// Protocol combina um Codec com o Framer e a porta usados por um protocolo.
+type Protocol struct {
+	Nome      string
+	Porta     string
+	Codec     Codec
+	NewFramer func(rw io.ReadWriter, protocolo string) Framer
+}
+
+// Protocols lista os protocolos conhecidos por NewProtocolClient.
+var Protocols = map[string]Protocol{
+	StringProtocol.Nome:  StringProtocol,
+	JSONProtocol.Nome:    JSONProtocol,
+	ProtoProtocol.Nome:   ProtoProtocol,
+	MsgpackProtocol.Nome: MsgpackProtocol,
+	CborProtocol.Nome:    CborProtocol,
+}
+
+// NewProtocolClient cria o cliente do protocolo registrado em Protocols.
+func NewProtocolClient(nome string) (*CodecClient, error) {
+	p, ok := Protocols[nome]
+	if !ok {
+		return nil, newError(ErrUnsupported, nil, "protocolo '%s' desconhecido", nome)
+	}
+	return NewCodecClient(p), nil
+}
+
+// CodecClient implementa o Client para qualquer Protocol: conexão, prazos,
+// métricas, rastreamento e compressão ficam aqui, e o Codec só traduz as
+// mensagens.
+type CodecClient struct {
+	baseClient
+	proto  Protocol
+	framer Framer
+}
+
+func NewCodecClient(p Protocol) *CodecClient {
+	return &CodecClient{baseClient: baseClient{protocol: p.Nome}, proto: p}
+}
+
+// SetPort troca a porta padrão do protocolo nas próximas conexões.
+func (c *CodecClient) SetPort(porta string) {
+	c.proto.Porta = porta
+}
+
+func (c *CodecClient) Connect(ctx context.Context, host string) error {
+	if err := c.baseClient.Connect(ctx, host, c.proto.Porta); err != nil {
+		return err
+	}
+	c.framer = c.proto.NewFramer(c.conn, c.protocol)
+	return nil
+}
+
+func (c *CodecClient) sendAndReceive(ctx context.Context, call Call) (_ any, err error) {
+	done := c.track(ctx, call.Op)
+	defer func() { done(errorKind(err)) }()
+
+	if err := c.setDeadline(ctx); err != nil {
+		return nil, err
+	}
+	msg, err := c.proto.Codec.Encode(call)
+	if err != nil {
+		return nil, newError(ErrEncode, err, "%s: falha ao serializar requisição", c.protocol)
+	}
+	if err := c.framer.WriteFrame(msg, c.codificacao); err != nil {
+		return nil, newError(ErrSend, err, "%s: falha ao enviar mensagem", c.protocol)
+	}
+	resp, alg, err := c.framer.ReadFrame(c.frameLimit())
+	if err != nil {
+		return nil, newError(ErrReceive, err, "%s: falha ao ler resposta", c.protocol)
+	}
+	c.negotiated(alg)
+	return c.proto.Codec.Decode(call, resp)
+}
+
+// invoke executa op aplicando o FallbackCodec do protocolo, se houver.
+func (c *CodecClient) invoke(ctx context.Context, op string, req any) (any, error) {
+	call := Call{
+		Op:               op,
+		Req:              req,
+		TraceContext:     injectTraceContext(ctx),
+		AceitaCompressao: c.acceptCompression(),
+		Local:            c.location,
+	}
+	for {
+		resp, err := c.sendAndReceive(ctx, call)
+		if err == nil {
+			return resp, nil
+		}
+		fc, ok := c.proto.Codec.(FallbackCodec)
+		if !ok {
+			return nil, err
+		}
+		next, resp, ok := fc.Fallback(call, err)
+		if !ok {
+			return nil, err
+		}
+		if next == nil {
+			return resp, nil
+		}
+		call = *next
+		call.AceitaCompressao = c.acceptCompression()
+	}
+}
+
+func invokeCodec[T any](ctx context.Context, c *CodecClient, op string, req any) (T, error) {
+	var zero T
+	res, err := c.invoke(ctx, op, req)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := res.(T)
+	if !ok {
+		return zero, newError(ErrInvalidResponse, nil, "%s: resposta inesperada do codec para a operação '%s': %T", c.protocol, op, res)
+	}
+	return v, nil
+}
+
+func (c *CodecClient) Auth(ctx context.Context, alunoID string) (_ *AuthResponse, err error) {
+	ctx, end := c.startSpan(ctx, "Auth", OpAuth)
+	defer func() { end(err) }()
+	return invokeCodec[*AuthResponse](ctx, c, OpAuth, AuthRequest{AlunoID: alunoID})
+}
+
+func (c *CodecClient) OpEcho(ctx context.Context, token, msg string) (_ *EchoResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpEcho", OpEcho)
+	defer func() { end(err) }()
+	return invokeCodec[*EchoResponse](ctx, c, OpEcho, EchoRequest{Token: token, Mensagem: msg})
+}
+
+func (c *CodecClient) OpSoma(ctx context.Context, token string, numeros []string) (_ *SomaResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpSoma", OpSoma)
+	defer func() { end(err) }()
+	return invokeCodec[*SomaResponse](ctx, c, OpSoma, SomaRequest{Token: token, Numeros: numeros})
+}
+
+func (c *CodecClient) OpTimestamp(ctx context.Context, token string) (_ *TimestampResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpTimestamp", OpTimestamp)
+	defer func() { end(err) }()
+	return invokeCodec[*TimestampResponse](ctx, c, OpTimestamp, TimestampRequest{Token: token})
+}
+
+func (c *CodecClient) OpStatus(ctx context.Context, token string, detalhado bool) (_ *StatusResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpStatus", OpStatus)
+	defer func() { end(err) }()
+	return invokeCodec[*StatusResponse](ctx, c, OpStatus, StatusRequest{Token: token, Detalhado: detalhado})
+}
+
+func (c *CodecClient) OpHistorico(ctx context.Context, token string, limite int) (_ *HistoricoResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpHistorico", OpHistorico)
+	defer func() { end(err) }()
+	return invokeCodec[*HistoricoResponse](ctx, c, OpHistorico, HistoricoRequest{Token: token, Limite: limite})
+}
+
+func (c *CodecClient) OpHistoricoPagina(ctx context.Context, token string, p Pagina) (_ *HistoricoResponse, err error) {
+	ctx, end := c.startSpan(ctx, "OpHistoricoPagina", OpHistorico)
+	defer func() { end(err) }()
+	return invokeCodec[*HistoricoResponse](ctx, c, OpHistorico, HistoricoRequest{Token: token, Limite: p.Limite, Offset: p.Offset, Cursor: p.Cursor})
+}
+
+func (c *CodecClient) Info(ctx context.Context, token, tipo string) (_ *InfoResponse, err error) {
+	ctx, end := c.startSpan(ctx, "Info", OpInfo)
+	defer func() { end(err) }()
+	return invokeCodec[*InfoResponse](ctx, c, OpInfo, InfoRequest{Token: token, Tipo: tipo})
+}
+
+func (c *CodecClient) Logout(ctx context.Context, token string) (err error) {
+	ctx, end := c.startSpan(ctx, "Logout", OpLogout)
+	defer func() { end(err) }()
+	_, err = c.invoke(ctx, OpLogout, LogoutRequest{Token: token})
+	return err
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// SetCompression ativa a compressão nos transportes JSON e protobuf. Os
+// algoritmos (wire.CompressaoZstd, wire.CompressaoGzip, wire.CompressaoSnappy)
+// são oferecidos ao servidor em ordem de preferência, e as requisições só
+// passam a ser comprimidas depois que o servidor confirma um deles; com
+// servidores sem suporte nada muda. Sem argumentos a compressão é desativada.
+// O protocolo String não tem compressão e ignora a configuração.
+func (c *baseClient) SetCompression(algs ...string) error {
+	valid, err := wire.ParseCompressoes(strings.Join(algs, ","))
+	if err != nil {
+		return err
+	}
+	c.compressao = valid
+	c.codificacao = ""
+	return nil
+}
+
+// Compression devolve o algoritmo negociado na conexão atual (vazio se não
+// houver).
+func (c *baseClient) Compression() string {
+	return c.codificacao
+}
+
+// acceptCompression devolve a oferta a enviar ao servidor enquanto a
+// compressão ainda não foi negociada.
+func (c *baseClient) acceptCompression() string {
+	if len(c.compressao) == 0 || c.codificacao != "" {
+		return ""
+	}
+	return strings.Join(c.compressao, ",")
+}
+
+// negotiated registra o algoritmo confirmado pelo servidor numa resposta.
+func (c *baseClient) negotiated(alg string) {
+	if alg != "" && slices.Contains(c.compressao, alg) {
+		c.codificacao = alg
+	}
+}
+
+func decompressError(err error, protocolo string, limite int64) error {
+	if errors.Is(err, wire.ErrDescompressaoLimite) {
+		return &FrameTooLargeError{Protocolo: protocolo, Tamanho: -1, Limite: limite}
+	}
+	return err
+}
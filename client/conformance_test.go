@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// TestConformance executa a mesma sequência com todos os protocolos de
+// Protocols contra o wire.TestServer e confere que as respostas decodificadas
+// são iguais, independentemente do formato no fio.
+func TestConformance(t *testing.T) {
+	quietLog(t)
+	for _, nome := range slices.Sorted(maps.Keys(Protocols)) {
+		t.Run(nome, func(t *testing.T) {
+			conformance(t, newTestClient(t, nome), nome)
+		})
+		if nome == "string" {
+			continue
+		}
+		t.Run(nome+"/compressao", func(t *testing.T) {
+			host, porta := startTestServer(t, nome, wire.Compressoes)
+			c, _ := NewProtocolClient(nome)
+			c.SetPort(porta)
+			if err := c.SetCompression(wire.CompressaoZstd); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Connect(context.Background(), host); err != nil {
+				t.Fatal(err)
+			}
+			defer c.Disconnect()
+			conformance(t, c, nome)
+			if c.codificacao != wire.CompressaoZstd {
+				t.Errorf("compressão negociada = %q, esperado %q", c.codificacao, wire.CompressaoZstd)
+			}
+		})
+	}
+}
+
+func conformance(t *testing.T, c Client, protocolo string) {
+	ctx := context.Background()
+	auth, err := c.Auth(ctx, "520402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Token == "" || auth.Matricula != "520402" || auth.Nome != "Aluno 520402" {
+		t.Errorf("Auth = %+v", auth)
+	}
+
+	// Uma mensagem longa passa do mínimo para comprimir.
+	msg := "olá, mundo"
+	for len(msg) < 2*wire.MinCompressSize {
+		msg += " ção"
+	}
+	echo, err := c.OpEcho(ctx, auth.Token, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo.Eco != msg || echo.MensagemOriginal != msg || echo.Tamanho != len(msg) || len(echo.HashMD5) != 32 {
+		t.Errorf("OpEcho = {Eco: %d bytes, Tamanho: %d, HashMD5: %q}", len(echo.Eco), echo.Tamanho, echo.HashMD5)
+	}
+
+	soma, err := c.OpSoma(ctx, auth.Token, []string{"1", "2", "-3", "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SomaResponse{Soma: 10, Media: 2.5, Maximo: 10, Minimo: -3, NumerosProcessados: 4}); *soma != want {
+		t.Errorf("OpSoma = %+v, esperado %+v", *soma, want)
+	}
+
+	ts, err := c.OpTimestamp(ctx, auth.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(ts.Horario); d < -time.Minute || d > time.Minute {
+		t.Errorf("OpTimestamp = %v, longe de agora", ts.Horario)
+	}
+	if ts.FusoDesconhecido {
+		t.Errorf("fuso do servidor %q desconhecido", ts.TimezoneServidor)
+	}
+
+	status, err := c.OpStatus(ctx, auth.Token, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "ATIVO" || status.OperacoesProcessadas != 3 {
+		t.Errorf("OpStatus = %+v", status)
+	}
+	banco := status.Estatisticas.Banco
+	if banco.SessoesAtivas != 1 || banco.VersaoProtocolo != protocolo {
+		t.Errorf("estatísticas do banco = %+v", banco)
+	}
+
+	hist, err := c.OpHistorico(ctx, auth.Token, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var comandos []string
+	for _, op := range hist.Operacoes {
+		comandos = append(comandos, op.Comando)
+		// O String só traz os nomes das operações.
+		if _, err := op.Horario(); err != nil && protocolo != "string" {
+			t.Errorf("horário da operação %s: %v", op.Comando, err)
+		}
+	}
+	if want := []string{"echo", "soma", "timestamp", "status"}; !slices.Equal(comandos, want) {
+		t.Errorf("histórico = %q, esperado %q", comandos, want)
+	}
+	if e := hist.Estatisticas; e.TotalOperacoes != 4 || e.Sucessos != 4 || e.Falhas != 0 {
+		t.Errorf("estatísticas do histórico = %+v", e)
+	}
+
+	info, err := c.Info(ctx, auth.Token, "completo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(info.Capacidades, "historico") {
+		t.Errorf("Info = %+v", info)
+	}
+
+	if err := c.Logout(ctx, auth.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OpEcho(ctx, auth.Token, "depois do logout"); !IsInvalidToken(err) {
+		t.Errorf("OpEcho depois do logout: %v, esperado token inválido", err)
+	}
+}
+
+// TestNormalizeNumbers confere que os números decodificados de msgpack e
+// CBOR, que chegam como inteiros de vários tamanhos e floats de 16/32 bits,
+// ficam iguais aos do JSON depois de normalizeNumbers.
+func TestNormalizeNumbers(t *testing.T) {
+	resultado := map[string]any{
+		"pequeno":   1,
+		"negativo":  -3,
+		"grande":    int64(1) << 40,
+		"sem_sinal": uint16(60000),
+		"meio":      0.5,
+		"float":     2.625,
+		"lista":     []any{1, 2.5, map[string]any{"n": -1}},
+		"aninhado":  map[string]any{"total": 4, "texto": "4"},
+	}
+	b, _ := json.Marshal(resultado)
+	var want map[string]any
+	json.Unmarshal(b, &want)
+
+	for nome, ec := range wire.EnvelopeCodecs {
+		b, err := ec.Marshal(resultado)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := ec.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		normalizeNumbers(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: %#v\nesperado %#v", nome, got, want)
+		}
+	}
+}
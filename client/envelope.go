@@ -0,0 +1,32 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// MsgpackProtocol e CborProtocol usam os mesmos envelopes do JSON serializados
+// em MessagePack e CBOR, em frames com prefixo de tamanho como os do protobuf.
+var (
+	MsgpackProtocol = envelopeProtocol("msgpack", "8083")
+	CborProtocol    = envelopeProtocol("cbor", "8084")
+)
+
+func envelopeProtocol(nome, porta string) Protocol {
+	ec := wire.EnvelopeCodecs[nome]
+	return Protocol{
+		Nome:      nome,
+		Porta:     porta,
+		Codec:     &envelopeCodec{tag: strings.ToUpper(nome), marshal: ec.Marshal, unmarshal: ec.Unmarshal},
+		NewFramer: NewLengthPrefixFramer,
+	}
+}
+
+func NewMsgpackClient() *CodecClient {
+	return NewCodecClient(MsgpackProtocol)
+}
+
+func NewCborClient() *CodecClient {
+	return NewCodecClient(CborProtocol)
+}
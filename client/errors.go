@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+// Codigo identifica um erro do cliente independentemente do idioma da
+// mensagem: compare com errors.Is(err, client.ErrConnect), nunca pelo texto.
+type Codigo string
+
+func (c Codigo) Error() string {
+	return i18n.T(string(c))
+}
+
+const (
+	ErrResolve         Codigo = "falha ao resolver servidor"
+	ErrConnect         Codigo = "falha ao conectar"
+	ErrNotConnected    Codigo = "cliente não conectado"
+	ErrEncode          Codigo = "falha ao serializar requisição"
+	ErrSend            Codigo = "falha ao enviar mensagem"
+	ErrReceive         Codigo = "falha ao ler resposta"
+	ErrInvalidRequest  Codigo = "requisição inválida"
+	ErrInvalidResponse Codigo = "resposta inválida do servidor"
+	ErrUnsupported     Codigo = "operação não suportada"
+	ErrAuth            Codigo = "falha na autenticação"
+	ErrSessionClosed   Codigo = "sessão encerrada"
+	ErrNoHealthyServer Codigo = "nenhum servidor disponível"
+	ErrNoEndpoints     Codigo = "nenhum servidor encontrado"
+)
+
+// Error é um erro do cliente com mensagem traduzida pelo pacote i18n.
+// Mensagem (uma chave do catálogo, formatada com Args) detalha o Codigo e,
+// quando vazia, o próprio Codigo é a mensagem.
+type Error struct {
+	Codigo   Codigo
+	Mensagem string
+	Args     []any
+	Err      error
+}
+
+func newError(c Codigo, err error, msg string, args ...any) *Error {
+	return &Error{Codigo: c, Mensagem: msg, Args: args, Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Codigo)
+	if e.Mensagem != "" {
+		msg = e.Mensagem
+	}
+	msg = i18n.T(msg, e.Args...)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	c, ok := target.(Codigo)
+	return ok && c == e.Codigo
+}
+
+// ServerError é um erro reportado pelo próprio servidor (ERROR no String,
+// sucesso=false no JSON, campo "erro" no protobuf).
+type ServerError struct {
+	Operacao string
+	Mensagem string
+}
+
+func (e *ServerError) Error() string {
+	return i18n.T("erro do servidor: %s", e.Mensagem)
+}
+
+// FrameTooLargeError indica que o servidor enviou uma mensagem maior que o
+// limite configurado no cliente. Tamanho é -1 quando o tamanho total não é
+// conhecido (linhas do String e documentos JSON).
+type FrameTooLargeError struct {
+	Protocolo string
+	Tamanho   int64
+	Limite    int64
+}
+
+func (e *FrameTooLargeError) Error() string {
+	if e.Tamanho < 0 {
+		return i18n.T("mensagem do servidor (%s) excede o limite de %d bytes", e.Protocolo, e.Limite)
+	}
+	return i18n.T("mensagem do servidor (%s) de %d bytes excede o limite de %d bytes", e.Protocolo, e.Tamanho, e.Limite)
+}
+
+// IsInvalidToken informa se err é a recusa do servidor a um token inválido ou
+// expirado.
+func IsInvalidToken(err error) bool {
+	var srvErr *ServerError
+	if !errors.As(err, &srvErr) {
+		return false
+	}
+	msg := strings.ToLower(srvErr.Mensagem)
+	if !strings.Contains(msg, "token") {
+		return false
+	}
+	for _, s := range []string{"inválido", "invalido", "invalid", "expirado", "expired"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
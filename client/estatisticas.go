@@ -0,0 +1,209 @@
+package client
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Estatisticas reúne as estatísticas devolvidas por OpStatus (estatísticas do
+// banco) e OpHistorico. Campos que o servidor não informa ficam zerados, e as
+// chaves desconhecidas ficam em Extra com o valor recebido.
+type Estatisticas struct {
+	TotalOperacoes int
+	Sucessos       int
+	Falhas         int
+	// PorOperacao conta as operações por nome (echo, soma...).
+	PorOperacao map[string]int
+	Banco       EstatisticasBanco
+	Extra       map[string]any
+
+	// informadas guarda os nomes canônicos das estatísticas recebidas, para
+	// que Map devolva também as que vieram zeradas.
+	informadas map[string]bool
+}
+
+type EstatisticasBanco struct {
+	SessoesAtivas   int
+	TotalAlunos     int
+	VersaoProtocolo string
+}
+
+// Nomes aceitos para cada estatística conhecida; o primeiro é o usado em Map.
+var (
+	chavesTotal       = []string{"total_operacoes", "total", "operacoes_total"}
+	chavesSucessos    = []string{"sucessos", "sucesso", "operacoes_sucesso", "operacoes_sucedidas"}
+	chavesFalhas      = []string{"falhas", "falha", "erros", "erro", "operacoes_falha", "operacoes_erro"}
+	chavesPorOperacao = []string{"por_operacao", "operacoes_por_tipo", "por_tipo"}
+	chavesSessoes     = []string{"sessoes_ativas"}
+	chavesAlunos      = []string{"total_alunos", "alunos", "total_usuarios", "usuarios"}
+	chavesVersao      = []string{"versao_protocolo", "versao"}
+)
+
+func (e Estatisticas) IsZero() bool {
+	return e.TotalOperacoes == 0 && e.Sucessos == 0 && e.Falhas == 0 &&
+		len(e.PorOperacao) == 0 && e.Banco == (EstatisticasBanco{}) && len(e.Extra) == 0 &&
+		len(e.informadas) == 0
+}
+
+// Map devolve as estatísticas com os nomes canônicos (total_operacoes,
+// sucessos, ...), omitindo as que não foram informadas, junto com Extra. Em
+// estatísticas montadas à mão, sem passar pelo servidor, os zeros contam como
+// não informados.
+func (e Estatisticas) Map() map[string]any {
+	m := make(map[string]any, len(e.Extra)+7)
+	maps.Copy(m, e.Extra)
+	for chave, v := range map[string]int{
+		chavesTotal[0]:    e.TotalOperacoes,
+		chavesSucessos[0]: e.Sucessos,
+		chavesFalhas[0]:   e.Falhas,
+		chavesSessoes[0]:  e.Banco.SessoesAtivas,
+		chavesAlunos[0]:   e.Banco.TotalAlunos,
+	} {
+		if v != 0 || e.informadas[chave] {
+			m[chave] = v
+		}
+	}
+	if e.Banco.VersaoProtocolo != "" || e.informadas[chavesVersao[0]] {
+		m[chavesVersao[0]] = e.Banco.VersaoProtocolo
+	}
+	if len(e.PorOperacao) > 0 || e.informadas[chavesPorOperacao[0]] {
+		porOp := make(map[string]any, len(e.PorOperacao))
+		for op, n := range e.PorOperacao {
+			porOp[op] = n
+		}
+		m[chavesPorOperacao[0]] = porOp
+	}
+	return m
+}
+
+// parseEstatisticas interpreta um objeto de estatísticas já decodificado
+// (JSON, msgpack, cbor). Os números podem vir como número ou texto.
+func parseEstatisticas(m map[string]any) Estatisticas {
+	var e Estatisticas
+	informa := func(chaves []string) {
+		if e.informadas == nil {
+			e.informadas = make(map[string]bool)
+		}
+		e.informadas[chaves[0]] = true
+	}
+	for k, v := range m {
+		chave := strings.ToLower(strings.TrimSpace(k))
+		switch {
+		case slices.Contains(chavesTotal, chave) && isNumero(v):
+			e.TotalOperacoes = toInt(v)
+			informa(chavesTotal)
+		case slices.Contains(chavesSucessos, chave) && isNumero(v):
+			e.Sucessos = toInt(v)
+			informa(chavesSucessos)
+		case slices.Contains(chavesFalhas, chave) && isNumero(v):
+			e.Falhas = toInt(v)
+			informa(chavesFalhas)
+		case slices.Contains(chavesSessoes, chave) && isNumero(v):
+			e.Banco.SessoesAtivas = toInt(v)
+			informa(chavesSessoes)
+		case slices.Contains(chavesAlunos, chave) && isNumero(v):
+			e.Banco.TotalAlunos = toInt(v)
+			informa(chavesAlunos)
+		case slices.Contains(chavesVersao, chave):
+			e.Banco.VersaoProtocolo = toString(v)
+			informa(chavesVersao)
+		case slices.Contains(chavesPorOperacao, chave) && parsePorOperacao(v) != nil:
+			e.PorOperacao = parsePorOperacao(v)
+			informa(chavesPorOperacao)
+		default:
+			if e.Extra == nil {
+				e.Extra = make(map[string]any)
+			}
+			e.Extra[k] = v
+		}
+	}
+	return e
+}
+
+// parseEstatisticasTexto interpreta as estatísticas serializadas num campo de
+// texto, como no protobuf: JSON ou repr de dicionário Python. Se nenhum dos
+// dois servir, o texto original fica em Extra["raw_stats"].
+func parseEstatisticasTexto(s string) Estatisticas {
+	texto := strings.TrimSpace(s)
+	if texto == "" {
+		return Estatisticas{}
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(texto), &m); err == nil {
+		return parseEstatisticas(m)
+	}
+	if v, err := parsePythonRepr(texto); err == nil {
+		if m, ok := v.(map[string]any); ok {
+			return parseEstatisticas(m)
+		}
+	}
+	return Estatisticas{Extra: map[string]any{"raw_stats": s}}
+}
+
+// parseEstatisticasKV interpreta os campos chave=valor do protocolo String;
+// por_operacao vem como "echo:3,soma:1".
+func parseEstatisticasKV(parts []string) Estatisticas {
+	m := make(map[string]any, len(parts))
+	for _, p := range parts {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			m[k] = v
+		}
+	}
+	return parseEstatisticas(m)
+}
+
+func parsePorOperacao(v any) map[string]int {
+	porOp := make(map[string]int)
+	switch v := v.(type) {
+	case map[string]any:
+		for op, n := range v {
+			if isNumero(n) {
+				porOp[op] = toInt(n)
+			}
+		}
+	case string:
+		for _, par := range strings.Split(v, ",") {
+			op, n, ok := strings.Cut(par, ":")
+			if !ok || !isNumero(n) {
+				return nil
+			}
+			porOp[strings.TrimSpace(op)] = toInt(n)
+		}
+	default:
+		return nil
+	}
+	return porOp
+}
+
+func isNumero(v any) bool {
+	switch v := normalizeNumber(v).(type) {
+	case float64:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return err == nil
+	}
+	return false
+}
+
+func toInt(v any) int {
+	switch v := normalizeNumber(v).(type) {
+	case float64:
+		return int(v)
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return int(f)
+	}
+	return 0
+}
+
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
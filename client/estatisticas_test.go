@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEstatisticasMapKeepsReceivedZeros(t *testing.T) {
+	quietLog(t)
+	for _, protocolo := range []string{"json", "proto", "msgpack"} {
+		t.Run(protocolo, func(t *testing.T) {
+			s := sessaoComOperacoes(t, newTestClient(t, protocolo), 2)
+			h, err := s.Historico(context.Background(), Limit(10))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Todas as operações deram certo, então o servidor manda falhas=0.
+			m := h.Estatisticas.Map()
+			if v, ok := m["falhas"]; !ok || v != 0 {
+				t.Errorf("falhas = %v (presente: %v), quer 0", v, ok)
+			}
+			if m["sucessos"] != h.Estatisticas.TotalOperacoes {
+				t.Errorf("sucessos = %v, quer %d", m["sucessos"], h.Estatisticas.TotalOperacoes)
+			}
+		})
+	}
+}
+
+func TestEstatisticasMapOmitsMissing(t *testing.T) {
+	e := parseEstatisticas(map[string]any{"total": 3.0, "erros": 0.0})
+	want := map[string]any{"total_operacoes": 3, "falhas": 0}
+	if got := e.Map(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, quer %v", got, want)
+	}
+	if e := (Estatisticas{TotalOperacoes: 2}); !reflect.DeepEqual(e.Map(), map[string]any{"total_operacoes": 2}) {
+		t.Errorf("Map() = %v", e.Map())
+	}
+	if parseEstatisticas(map[string]any{"falhas": 0.0}).IsZero() {
+		t.Error("IsZero() com falhas=0 recebido")
+	}
+}
+
+func TestParseEstatisticasTexto(t *testing.T) {
+	tests := []struct {
+		nome  string
+		texto string
+		want  map[string]any
+	}{
+		{"json", `{"total": 2, "falhas": 0}`, map[string]any{"total_operacoes": 2, "falhas": 0}},
+		{
+			"repr",
+			`{'total_operacoes': 4, 'sucessos': 4, 'falhas': 0, 'por_operacao': {'echo': 3, 'soma': 1}}`,
+			map[string]any{
+				"total_operacoes": 4, "sucessos": 4, "falhas": 0,
+				"por_operacao": map[string]any{"echo": 3, "soma": 1},
+			},
+		},
+		{
+			// Aspas e palavras reservadas dentro do texto não podem ser trocadas.
+			"repr com texto",
+			`{'versao': "1.0 d'O True", 'ativo': True, 'obs': None, 'nota': 'it\'s False', 'ids': (1, 2.5, -3)}`,
+			map[string]any{
+				"versao_protocolo": "1.0 d'O True", "ativo": true, "obs": nil,
+				"nota": "it's False", "ids": []any{1.0, 2.5, -3.0},
+			},
+		},
+		{"vazio", "  ", map[string]any{}},
+		{"inválido", "{'total': 1", map[string]any{"raw_stats": "{'total': 1"}},
+		{"texto solto", " ok 'x' ", map[string]any{"raw_stats": " ok 'x' "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			if got := parseEstatisticasTexto(tt.texto).Map(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEstatisticasTexto(%q).Map() = %v, quer %v", tt.texto, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePythonReprErrors(t *testing.T) {
+	for _, s := range []string{"", "{'a' 1}", "[1 2]", "'sem fim", "{'a': 1} x", "nada", "'\\x4'"} {
+		if v, err := parsePythonRepr(s); err == nil {
+			t.Errorf("parsePythonRepr(%q) = %v, quer erro", s, v)
+		}
+	}
+}
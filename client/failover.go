@@ -0,0 +1,368 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Strategy string
+
+const (
+	RoundRobin   Strategy = "round-robin"
+	LeastLatency Strategy = "least-latency"
+)
+
+// Servidores marcados como indisponíveis voltam a ser candidatos depois deste
+// intervalo, mesmo sem uma sonda bem-sucedida.
+const unhealthyCooldown = 30 * time.Second
+
+// DefaultProbeTimeout limita cada sonda quando ProbeTimeout não é definido.
+const DefaultProbeTimeout = 5 * time.Second
+
+type EndpointHealth struct {
+	Host     string
+	Saudavel bool
+	// Latencia é o tempo de resposta do OpStatus da última sonda; zero até a
+	// primeira sonda.
+	Latencia    time.Duration
+	UltimaFalha time.Time
+	UltimoErro  error
+}
+
+// FailoverClient distribui as conexões entre vários servidores equivalentes e,
+// quando o servidor atual falha (timeout ou erro de conexão), conecta-se ao
+// próximo, autentica-se de novo e repete a operação, se ela for idempotente.
+// O token devolvido no primeiro Auth continua válido para o chamador: ele é
+// traduzido para o token do servidor atual.
+type FailoverClient struct {
+	Strategy Strategy
+	Logger   *log.Logger
+	// ProbeAluno é a matrícula usada nas sondas; vazia, vale a do último
+	// Auth.
+	ProbeAluno   string
+	ProbeTimeout time.Duration
+
+	factory func() Client
+
+	mu          sync.Mutex
+	hosts       []string
+	health      map[string]*EndpointHealth
+	proximo     int
+	atual       Client
+	hostAtual   string
+	alunoID     string
+	tokenSessao string
+	tokenAtual  string
+}
+
+// NewFailoverClient cria o cliente para os servidores hosts; cada conexão usa
+// um Client novo de factory.
+func NewFailoverClient(factory func() Client, strategy Strategy, hosts ...string) *FailoverClient {
+	f := &FailoverClient{
+		Strategy: strategy,
+		Logger:   log.Default(),
+		factory:  factory,
+		health:   make(map[string]*EndpointHealth),
+	}
+	for _, h := range hosts {
+		if _, ok := f.health[h]; ok || h == "" {
+			continue
+		}
+		f.hosts = append(f.hosts, h)
+		f.health[h] = &EndpointHealth{Host: h, Saudavel: true}
+	}
+	return f
+}
+
+func (f *FailoverClient) Host() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.hostAtual
+}
+
+func (f *FailoverClient) Health() []EndpointHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]EndpointHealth, 0, len(f.hosts))
+	for _, h := range f.hosts {
+		out = append(out, *f.health[h])
+	}
+	return out
+}
+
+// Connect conecta-se ao primeiro servidor disponível segundo a estratégia. Os
+// servidores são os de NewFailoverClient; host só identifica o conjunto para
+// o chamador e não é usado.
+func (f *FailoverClient) Connect(ctx context.Context, host string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.hosts) == 0 {
+		return newError(ErrNoEndpoints, nil, "failover: nenhum servidor informado")
+	}
+	return f.connectLocked(ctx, "")
+}
+
+func (f *FailoverClient) candidatesLocked(excluir string) []string {
+	n := len(f.hosts)
+	var saudaveis, indisponiveis []string
+	for i := 0; i < n; i++ {
+		h := f.hosts[(f.proximo+i)%n]
+		if h == excluir {
+			continue
+		}
+		st := f.health[h]
+		if st.Saudavel || time.Since(st.UltimaFalha) > unhealthyCooldown {
+			saudaveis = append(saudaveis, h)
+		} else {
+			indisponiveis = append(indisponiveis, h)
+		}
+	}
+	if f.Strategy == LeastLatency {
+		slices.SortStableFunc(saudaveis, func(a, b string) int {
+			return cmp.Compare(f.health[a].Latencia, f.health[b].Latencia)
+		})
+	}
+	f.proximo = (f.proximo + 1) % n
+	return append(saudaveis, indisponiveis...)
+}
+
+func (f *FailoverClient) markLocked(host string, latencia time.Duration, err error) {
+	st := f.health[host]
+	if err != nil {
+		st.Saudavel = false
+		st.UltimaFalha = time.Now()
+		st.UltimoErro = err
+		return
+	}
+	st.Saudavel = true
+	st.UltimoErro = nil
+	if latencia > 0 {
+		st.Latencia = latencia
+	}
+}
+
+func (f *FailoverClient) connectLocked(ctx context.Context, excluir string) error {
+	if f.atual != nil {
+		f.atual.Disconnect()
+		f.atual = nil
+	}
+
+	var errs []error
+	for _, h := range f.candidatesLocked(excluir) {
+		c := f.factory()
+		err := c.Connect(ctx, h)
+		f.markLocked(h, 0, err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.atual, f.hostAtual = c, h
+		return nil
+	}
+	return fmt.Errorf("failover: %w: %w", ErrNoHealthyServer, errors.Join(errs...))
+}
+
+// failoverLocked troca de servidor e, se já havia sessão, autentica de novo.
+func (f *FailoverClient) failoverLocked(ctx context.Context, causa error) error {
+	anterior := f.hostAtual
+	f.markLocked(anterior, 0, causa)
+	f.Logger.Printf("[failover] servidor %s falhou (%v); trocando de servidor...", anterior, causa)
+
+	if err := f.connectLocked(ctx, anterior); err != nil {
+		return err
+	}
+	if f.alunoID != "" && f.tokenSessao != "" {
+		resp, err := f.atual.Auth(ctx, f.alunoID)
+		if err != nil {
+			return newError(ErrAuth, err, "failover: falha ao reautenticar em %s", f.hostAtual)
+		}
+		f.tokenAtual = resp.Token
+	}
+	f.Logger.Printf("[failover] sessão retomada em %s", f.hostAtual)
+	return nil
+}
+
+func (f *FailoverClient) translateLocked(token string) string {
+	if token != "" && token == f.tokenSessao {
+		return f.tokenAtual
+	}
+	return token
+}
+
+// failoverCall executa call no servidor atual. Só as operações idempotentes
+// (IsIdempotent) são repetidas no próximo servidor; nas demais o erro volta
+// ao chamador, que não sabe se o servidor chegou a executá-las.
+func failoverCall[T any](ctx context.Context, f *FailoverClient, op, token string, call func(c Client, token string) (T, error)) (T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var zero T
+	if f.atual == nil {
+		return zero, newError(ErrNotConnected, nil, "failover: cliente não conectado")
+	}
+	for tentativa := 0; ; tentativa++ {
+		res, err := call(f.atual, f.translateLocked(token))
+		if err == nil {
+			f.markLocked(f.hostAtual, 0, nil)
+			return res, nil
+		}
+		if !IsRetryable(err) || ctx.Err() != nil || tentativa >= len(f.hosts)-1 {
+			return res, err
+		}
+		if !IsIdempotent(op) {
+			f.markLocked(f.hostAtual, 0, err)
+			return res, err
+		}
+		if ferr := f.failoverLocked(ctx, err); ferr != nil {
+			return zero, errors.Join(err, ferr)
+		}
+	}
+}
+
+func (f *FailoverClient) Disconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.atual == nil {
+		return nil
+	}
+	err := f.atual.Disconnect()
+	f.atual = nil
+	return err
+}
+
+func (f *FailoverClient) Auth(ctx context.Context, alunoID string) (*AuthResponse, error) {
+	resp, err := failoverCall(ctx, f, OpAuth, "", func(c Client, _ string) (*AuthResponse, error) {
+		return c.Auth(ctx, alunoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.alunoID = alunoID
+	f.tokenSessao = resp.Token
+	f.tokenAtual = resp.Token
+	f.mu.Unlock()
+	return resp, nil
+}
+
+func (f *FailoverClient) OpEcho(ctx context.Context, token, msg string) (*EchoResponse, error) {
+	return failoverCall(ctx, f, OpEcho, token, func(c Client, token string) (*EchoResponse, error) {
+		return c.OpEcho(ctx, token, msg)
+	})
+}
+
+func (f *FailoverClient) OpSoma(ctx context.Context, token string, numeros []string) (*SomaResponse, error) {
+	return failoverCall(ctx, f, OpSoma, token, func(c Client, token string) (*SomaResponse, error) {
+		return c.OpSoma(ctx, token, numeros)
+	})
+}
+
+func (f *FailoverClient) OpTimestamp(ctx context.Context, token string) (*TimestampResponse, error) {
+	return failoverCall(ctx, f, OpTimestamp, token, func(c Client, token string) (*TimestampResponse, error) {
+		return c.OpTimestamp(ctx, token)
+	})
+}
+
+func (f *FailoverClient) OpStatus(ctx context.Context, token string, detalhado bool) (*StatusResponse, error) {
+	return failoverCall(ctx, f, OpStatus, token, func(c Client, token string) (*StatusResponse, error) {
+		return c.OpStatus(ctx, token, detalhado)
+	})
+}
+
+func (f *FailoverClient) OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error) {
+	return failoverCall(ctx, f, OpHistorico, token, func(c Client, token string) (*HistoricoResponse, error) {
+		return c.OpHistorico(ctx, token, limite)
+	})
+}
+
+func (f *FailoverClient) OpHistoricoPagina(ctx context.Context, token string, p Pagina) (*HistoricoResponse, error) {
+	return failoverCall(ctx, f, OpHistorico, token, func(c Client, token string) (*HistoricoResponse, error) {
+		return c.OpHistoricoPagina(ctx, token, p)
+	})
+}
+
+func (f *FailoverClient) Info(ctx context.Context, token, tipo string) (*InfoResponse, error) {
+	return failoverCall(ctx, f, OpInfo, token, func(c Client, token string) (*InfoResponse, error) {
+		return c.Info(ctx, token, tipo)
+	})
+}
+
+func (f *FailoverClient) Logout(ctx context.Context, token string) error {
+	_, err := failoverCall(ctx, f, OpLogout, token, func(c Client, token string) (struct{}, error) {
+		return struct{}{}, c.Logout(ctx, token)
+	})
+	f.mu.Lock()
+	if token == f.tokenSessao {
+		f.tokenSessao, f.tokenAtual = "", ""
+	}
+	f.mu.Unlock()
+	return err
+}
+
+// Probe verifica cada servidor com uma sessão curta e separada (Auth,
+// OpStatus e Logout), limitada por ProbeTimeout, e registra a
+// disponibilidade e o tempo de resposta do OpStatus, usado por LeastLatency.
+// Sem ProbeAluno nem um Auth anterior não há com quem autenticar e a sonda
+// não é feita.
+func (f *FailoverClient) Probe(ctx context.Context) {
+	f.mu.Lock()
+	hosts := slices.Clone(f.hosts)
+	aluno := cmp.Or(f.ProbeAluno, f.alunoID)
+	timeout := cmp.Or(f.ProbeTimeout, DefaultProbeTimeout)
+	f.mu.Unlock()
+	if aluno == "" {
+		return
+	}
+
+	for _, h := range hosts {
+		latencia, err := f.probe(ctx, h, aluno, timeout)
+		f.mu.Lock()
+		f.markLocked(h, latencia, err)
+		f.mu.Unlock()
+	}
+}
+
+func (f *FailoverClient) probe(ctx context.Context, host, aluno string, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c := f.factory()
+	if err := c.Connect(ctx, host); err != nil {
+		return 0, err
+	}
+	defer c.Disconnect()
+	auth, err := c.Auth(ctx, aluno)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Logout(ctx, auth.Token)
+
+	inicio := time.Now()
+	if _, err := c.OpStatus(ctx, auth.Token, false); err != nil {
+		return 0, err
+	}
+	return time.Since(inicio), nil
+}
+
+// StartHealthChecks executa Probe periodicamente até ctx ser cancelado.
+func (f *FailoverClient) StartHealthChecks(ctx context.Context, intervalo time.Duration) {
+	go func() {
+		t := time.NewTicker(intervalo)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				f.Probe(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// servidoresFalsos simula vários servidores com nomes lógicos ("a", "b"...)
+// sobre um único wire.TestServer local, registrando as operações de cada um.
+type servidoresFalsos struct {
+	porta string
+
+	mu       sync.Mutex
+	chamadas map[string][]string
+	falhas   map[string]map[string]bool // host -> operação -> falha de conexão
+	atraso   map[string]time.Duration   // atraso do OpStatus por host
+}
+
+func novosServidoresFalsos(t *testing.T) *servidoresFalsos {
+	_, porta := startTestServer(t, "json", nil)
+	return &servidoresFalsos{
+		porta:    porta,
+		chamadas: make(map[string][]string),
+		falhas:   make(map[string]map[string]bool),
+		atraso:   make(map[string]time.Duration),
+	}
+}
+
+func (s *servidoresFalsos) factory() Client {
+	c := NewCodecClient(JSONProtocol)
+	c.SetPort(s.porta)
+	var host string
+	return WithInterceptors(c, func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		if r, ok := req.(ConnectRequest); ok {
+			host, req = r.Host, ConnectRequest{Host: "127.0.0.1"}
+		}
+		s.mu.Lock()
+		s.chamadas[host] = append(s.chamadas[host], op)
+		falha, atraso := s.falhas[host][op], s.atraso[host]
+		s.mu.Unlock()
+		if falha {
+			return nil, io.EOF
+		}
+		if op == OpStatus && atraso > 0 {
+			select {
+			case <-time.After(atraso):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return next(ctx, op, req)
+	})
+}
+
+func (s *servidoresFalsos) ops(host string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.chamadas[host])
+}
+
+func (s *servidoresFalsos) limpa() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.chamadas)
+}
+
+func TestFailoverClientEndpoints(t *testing.T) {
+	quietLog(t)
+	_, porta := startTestServer(t, "json", nil)
+	factory := func() Client {
+		c := NewCodecClient(JSONProtocol)
+		c.SetPort(porta)
+		return c
+	}
+	// Nada escuta em 127.0.0.2: o cliente deve passar ao próximo servidor.
+	f := NewFailoverClient(factory, RoundRobin, "127.0.0.2", "127.0.0.1")
+	ctx := context.Background()
+	if err := f.Connect(ctx, "lab"); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Disconnect()
+	if f.Host() != "127.0.0.1" {
+		t.Fatalf("Host() = %q, esperado 127.0.0.1", f.Host())
+	}
+	auth, err := f.Auth(ctx, "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Probe(ctx)
+	for _, h := range f.Health() {
+		if saudavel := h.Host == "127.0.0.1"; h.Saudavel != saudavel {
+			t.Errorf("%s: Saudavel = %v, esperado %v", h.Host, h.Saudavel, saudavel)
+		}
+	}
+	// A sonda não mexe na sessão atual.
+	if _, err := f.OpEcho(ctx, auth.Token, "olá"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailoverClientSemServidores(t *testing.T) {
+	f := NewFailoverClient(func() Client { return NewCodecClient(JSONProtocol) }, RoundRobin)
+	if err := f.Connect(context.Background(), "lab"); !errors.Is(err, ErrNoEndpoints) {
+		t.Fatalf("Connect sem servidores = %v, esperado %s", err, ErrNoEndpoints)
+	}
+}
+
+func TestFailoverProbeUsesStatus(t *testing.T) {
+	quietLog(t)
+	srv := novosServidoresFalsos(t)
+	srv.atraso["a"] = 40 * time.Millisecond
+	srv.atraso["c"] = time.Second
+	f := NewFailoverClient(srv.factory, LeastLatency, "a", "b", "c")
+	f.ProbeAluno = "123"
+	f.ProbeTimeout = 200 * time.Millisecond
+
+	f.Probe(context.Background())
+	want := []string{OpConnect, OpAuth, OpStatus, OpLogout, OpDisconnect}
+	for _, h := range []string{"a", "b"} {
+		if got := srv.ops(h); !slices.Equal(got, want) {
+			t.Errorf("sonda em %s: %v, esperado %v", h, got, want)
+		}
+	}
+
+	health := f.Health()
+	if a, b := health[0], health[1]; !a.Saudavel || !b.Saudavel || a.Latencia < 40*time.Millisecond || b.Latencia >= a.Latencia {
+		t.Errorf("a = %+v, b = %+v; esperado b mais rápido que a", a, b)
+	}
+	// O OpStatus de c passa do tempo da sonda.
+	if c := health[2]; c.Saudavel || !errors.Is(c.UltimoErro, context.DeadlineExceeded) {
+		t.Errorf("c = %+v, esperado indisponível por timeout", c)
+	}
+
+	// LeastLatency escolhe o servidor mais rápido segundo a sonda.
+	if err := f.Connect(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Disconnect()
+	if f.Host() != "b" {
+		t.Errorf("Host() = %q, esperado b", f.Host())
+	}
+}
+
+func TestFailoverProbeWithoutAluno(t *testing.T) {
+	srv := novosServidoresFalsos(t)
+	f := NewFailoverClient(srv.factory, RoundRobin, "a")
+	f.Probe(context.Background())
+	if ops := srv.ops("a"); len(ops) != 0 {
+		t.Errorf("sonda sem matrícula executou %v", ops)
+	}
+}
+
+func TestFailoverOnlyRepeatsIdempotent(t *testing.T) {
+	quietLog(t)
+	srv := novosServidoresFalsos(t)
+	f := NewFailoverClient(srv.factory, RoundRobin, "a", "b")
+	ctx := context.Background()
+	if err := f.Connect(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Disconnect()
+	auth, err := f.Auth(ctx, "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A soma falha em a e não pode ser repetida em b: o servidor pode ter
+	// executado a operação antes de a conexão cair.
+	srv.falhas["a"] = map[string]bool{OpSoma: true, OpEcho: true}
+	srv.limpa()
+	if _, err := f.OpSoma(ctx, auth.Token, []string{"1", "2"}); !errors.Is(err, io.EOF) {
+		t.Fatalf("OpSoma = %v, esperado io.EOF", err)
+	}
+	if ops := srv.ops("b"); len(ops) != 0 {
+		t.Errorf("OpSoma repetida em b: %v", ops)
+	}
+
+	// O eco é idempotente: troca de servidor, reautentica e repete.
+	if _, err := f.OpEcho(ctx, auth.Token, "olá"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{OpConnect, OpAuth, OpEcho}; !slices.Equal(srv.ops("b"), want) {
+		t.Errorf("operações em b: %v, esperado %v", srv.ops("b"), want)
+	}
+	if f.Host() != "b" {
+		t.Errorf("Host() = %q, esperado b", f.Host())
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+type lineFramer struct {
+	reader    *bufio.Reader
+	writer    *bufio.Writer
+	protocolo string
+}
+
+// NewLineFramer delimita as mensagens por '\n', como o protocolo String. Não
+// há compressão.
+func NewLineFramer(rw io.ReadWriter, protocolo string) Framer {
+	return &lineFramer{reader: bufio.NewReader(rw), writer: bufio.NewWriter(rw), protocolo: protocolo}
+}
+
+func (f *lineFramer) WriteFrame(msg []byte, _ string) error {
+	f.writer.Write(msg)
+	f.writer.WriteByte('\n')
+	return f.writer.Flush()
+}
+
+func (f *lineFramer) ReadFrame(limite int64) ([]byte, string, error) {
+	line, err := readLine(f.reader, limite, f.protocolo)
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(line), "", nil
+}
+
+type jsonStreamFramer struct {
+	w         io.Writer
+	reader    *bufio.Reader
+	protocolo string
+}
+
+// NewJSONStreamFramer delimita as mensagens como documentos JSON em sequência;
+// mensagens comprimidas vão no envelope de wire.WrapJSON.
+func NewJSONStreamFramer(rw io.ReadWriter, protocolo string) Framer {
+	return &jsonStreamFramer{w: rw, reader: bufio.NewReader(rw), protocolo: protocolo}
+}
+
+func (f *jsonStreamFramer) WriteFrame(msg []byte, alg string) error {
+	doc, err := wire.WrapJSON(msg, alg)
+	if err != nil {
+		return err
+	}
+	_, err = f.w.Write(append(doc, '\n'))
+	return err
+}
+
+func (f *jsonStreamFramer) ReadFrame(limite int64) ([]byte, string, error) {
+	raw, err := readJSONDoc(f.reader, limite, f.protocolo)
+	if err != nil {
+		return nil, "", err
+	}
+	doc, alg, err := wire.UnwrapJSON(raw, limite)
+	if err != nil {
+		return nil, "", decompressError(err, f.protocolo, limite)
+	}
+	return doc, alg, nil
+}
+
+type lengthPrefixFramer struct {
+	rw        io.ReadWriter
+	protocolo string
+}
+
+// NewLengthPrefixFramer delimita as mensagens com um cabeçalho de 4 bytes
+// (BigEndian), estendido para as mensagens comprimidas (wire.AppendProtoFrame).
+func NewLengthPrefixFramer(rw io.ReadWriter, protocolo string) Framer {
+	return &lengthPrefixFramer{rw: rw, protocolo: protocolo}
+}
+
+func (f *lengthPrefixFramer) WriteFrame(msg []byte, alg string) error {
+	frame, err := wire.AppendProtoFrame(nil, msg, alg)
+	if err != nil {
+		return err
+	}
+	_, err = f.rw.Write(frame)
+	return err
+}
+
+func (f *lengthPrefixFramer) ReadFrame(limite int64) ([]byte, string, error) {
+	return readLengthPrefixed(f.rw, limite, f.protocolo)
+}
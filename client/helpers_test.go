@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"testing"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// startTestServer sobe um wire.TestServer do protocolo numa porta local e
+// devolve o host e a porta para o cliente.
+func startTestServer(t *testing.T, protocolo string, compressoes []string) (host, porta string) {
+	t.Helper()
+	srv, err := wire.NewTestServer(protocolo, compressoes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Logger = log.New(io.Discard, "", 0)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go srv.Serve(ctx, ln)
+	host, porta, _ = net.SplitHostPort(ln.Addr().String())
+	return host, porta
+}
+
+// newTestClient conecta um cliente do protocolo a um wire.TestServer.
+func newTestClient(t *testing.T, protocolo string) *CodecClient {
+	t.Helper()
+	host, porta := startTestServer(t, protocolo, nil)
+	c, err := NewProtocolClient(protocolo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPort(porta)
+	if err := c.Connect(context.Background(), host); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
+
+// quietLog descarta os logs de depuração dos codecs durante o teste.
+func quietLog(t *testing.T) {
+	t.Helper()
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(w) })
+}
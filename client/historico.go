@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+)
+
+const DefaultPageSize = 50
+
+type historicoOptions struct {
+	limite  int
+	pagina  int
+	offset  int
+	ops     []string
+	sucesso *bool
+	de, ate time.Time
+}
+
+type HistoricoOption func(*historicoOptions)
+
+// Limit limita as operações pedidas em Historico ou, em Operacoes, o total de
+// operações entregues depois dos filtros.
+func Limit(n int) HistoricoOption {
+	return func(o *historicoOptions) { o.limite = n }
+}
+
+// PageSize define quantas operações Operacoes pede por página (padrão
+// DefaultPageSize).
+func PageSize(n int) HistoricoOption {
+	return func(o *historicoOptions) { o.pagina = n }
+}
+
+// Offset pula as n operações mais recentes.
+func Offset(n int) HistoricoOption {
+	return func(o *historicoOptions) { o.offset = n }
+}
+
+// WithOperation mantém só as operações com um dos nomes informados.
+func WithOperation(nomes ...string) HistoricoOption {
+	return func(o *historicoOptions) { o.ops = append(o.ops, nomes...) }
+}
+
+// WithSuccess mantém só as operações bem-sucedidas (true) ou com falha (false).
+func WithSuccess(sucesso bool) HistoricoOption {
+	return func(o *historicoOptions) { o.sucesso = &sucesso }
+}
+
+// Between mantém só as operações no intervalo [de, ate); um extremo zero não
+// limita. Operações sem timestamp reconhecível (como as do protocolo String)
+// são descartadas.
+func Between(de, ate time.Time) HistoricoOption {
+	return func(o *historicoOptions) { o.de, o.ate = de, ate }
+}
+
+// Horario interpreta o timestamp da operação; sem fuso informado pelo
+// servidor, ele é tomado como UTC.
+func (op OperacaoInfo) Horario() (time.Time, error) {
+	return parseServerTimeIn(op.Timestamp, time.UTC)
+}
+
+func newHistoricoOptions(opts []HistoricoOption) historicoOptions {
+	o := historicoOptions{pagina: DefaultPageSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.pagina <= 0 {
+		o.pagina = DefaultPageSize
+	}
+	return o
+}
+
+func (o *historicoOptions) filtra() bool {
+	return len(o.ops) > 0 || o.sucesso != nil || !o.de.IsZero() || !o.ate.IsZero()
+}
+
+// match indica se op passa pelos filtros; antes é true quando op é anterior ao
+// início do intervalo, e portanto também todas as seguintes de Operacoes.
+func (o *historicoOptions) match(op OperacaoInfo) (ok, antes bool) {
+	if len(o.ops) > 0 && !slices.Contains(o.ops, op.Comando) {
+		return false, false
+	}
+	if o.sucesso != nil && op.Sucesso != *o.sucesso {
+		return false, false
+	}
+	if o.de.IsZero() && o.ate.IsZero() {
+		return true, false
+	}
+	t, err := op.Horario()
+	if err != nil {
+		return false, false
+	}
+	if !o.de.IsZero() && t.Before(o.de) {
+		return false, true
+	}
+	if !o.ate.IsZero() && !t.Before(o.ate) {
+		return false, false
+	}
+	return true, false
+}
+
+// Operacoes percorre o histórico da operação mais recente para a mais antiga,
+// buscando as páginas (PageSize) conforme o consumo e aplicando os filtros
+// (WithOperation, WithSuccess, Between). Se a primeira página vem cheia e sem
+// cursor, o servidor não pagina: o histórico é pedido de novo sem o campo
+// limite (nenhum protocolo envia limite zero), e um histórico ainda incompleto
+// segundo as estatísticas do servidor termina em erro. Um erro encerra a
+// sequência.
+func (s *Session) Operacoes(ctx context.Context, opts ...HistoricoOption) iter.Seq2[OperacaoInfo, error] {
+	o := newHistoricoOptions(opts)
+	return func(yield func(OperacaoInfo, error) bool) {
+		p := Pagina{Limite: o.pagina, Offset: o.offset}
+		busca := func() (*HistoricoResponse, error) {
+			return sessionCall(ctx, s, func(token string) (*HistoricoResponse, error) {
+				return s.Client.OpHistoricoPagina(ctx, token, p)
+			})
+		}
+		cursores := make(map[string]bool)
+		entregues := 0
+		for primeira := true; ; primeira = false {
+			resp, err := busca()
+			if err == nil && primeira && resp.ProximoCursor == "" && len(resp.Operacoes) >= p.Limite {
+				p.Limite = 0
+				if resp, err = busca(); err == nil && o.offset == 0 && resp.ProximoCursor == "" &&
+					resp.Estatisticas.TotalOperacoes > len(resp.Operacoes) {
+					err = newError(ErrInvalidResponse, nil, "histórico: o servidor devolveu %d de %d operações, sem cursor para continuar",
+						len(resp.Operacoes), resp.Estatisticas.TotalOperacoes)
+				}
+			}
+			if err != nil {
+				yield(OperacaoInfo{}, err)
+				return
+			}
+			for _, op := range slices.Backward(resp.Operacoes) {
+				ok, antes := o.match(op)
+				if antes {
+					return
+				}
+				if !ok {
+					continue
+				}
+				if !yield(op, nil) {
+					return
+				}
+				entregues++
+				if o.limite > 0 && entregues >= o.limite {
+					return
+				}
+			}
+			if resp.ProximoCursor == "" || len(resp.Operacoes) == 0 {
+				return
+			}
+			if cursores[resp.ProximoCursor] {
+				yield(OperacaoInfo{}, newError(ErrInvalidResponse, nil, "histórico: o servidor repetiu o cursor %q", resp.ProximoCursor))
+				return
+			}
+			cursores[resp.ProximoCursor] = true
+			p = Pagina{Limite: o.pagina, Cursor: resp.ProximoCursor}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// semPaginacao simula um servidor que ignora offset e cursor e devolve no
+// máximo maximo operações (0 para todas), informando total nas estatísticas.
+func semPaginacao(maximo, total int) Interceptor {
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		h, ok := req.(HistoricoRequest)
+		if !ok {
+			return next(ctx, op, req)
+		}
+		h.Offset, h.Cursor = 0, ""
+		if maximo > 0 && (h.Limite == 0 || h.Limite > maximo) {
+			h.Limite = maximo
+		}
+		res, err := next(ctx, op, h)
+		if resp, ok := res.(*HistoricoResponse); ok {
+			resp.ProximoCursor = ""
+			if total > 0 {
+				resp.Estatisticas.TotalOperacoes = total
+			}
+		}
+		return res, err
+	}
+}
+
+func sessaoComOperacoes(t *testing.T, c Client, n int) *Session {
+	t.Helper()
+	ctx := context.Background()
+	s, err := Login(ctx, c, "520402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range n {
+		if _, err := s.Echo(ctx, "x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func contaOperacoes(s *Session, opts ...HistoricoOption) (int, error) {
+	n := 0
+	for _, err := range s.Operacoes(context.Background(), opts...) {
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func TestOperacoesPaginated(t *testing.T) {
+	quietLog(t)
+	s := sessaoComOperacoes(t, newTestClient(t, "json"), 45)
+	if n, err := contaOperacoes(s, PageSize(10)); err != nil || n != 45 {
+		t.Errorf("Operacoes = %d, %v; esperado 45", n, err)
+	}
+	if n, err := contaOperacoes(s, PageSize(10), Limit(12)); err != nil || n != 12 {
+		t.Errorf("Operacoes com Limit(12) = %d, %v", n, err)
+	}
+}
+
+func TestOperacoesServerWithoutPagination(t *testing.T) {
+	quietLog(t)
+	c := WithInterceptors(newTestClient(t, "json"), semPaginacao(0, 0))
+	s := sessaoComOperacoes(t, c, 75)
+	// A primeira página vem cheia e sem cursor: o histórico inteiro é
+	// pedido, e já inclui o OpHistorico da primeira página.
+	if n, err := contaOperacoes(s, PageSize(DefaultPageSize)); err != nil || n != 76 {
+		t.Errorf("Operacoes = %d, %v; esperado 76", n, err)
+	}
+}
+
+// A segunda busca de Operacoes num servidor sem paginação pede o histórico
+// inteiro: o campo limite não vai na requisição, em vez de limite zero.
+func TestHistoricoRequestOmitsZeroLimit(t *testing.T) {
+	quietLog(t)
+	for _, limite := range []int{0, 50} {
+		call := Call{Op: OpHistorico, Req: HistoricoRequest{Token: "t", Limite: limite}}
+
+		msg, err := JSONProtocol.Codec.Encode(call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var env struct {
+			Parametros map[string]any `json:"parametros"`
+		}
+		if err := json.Unmarshal(msg, &env); err != nil {
+			t.Fatal(err)
+		}
+		v, ok := env.Parametros["limite"]
+		if limite == 0 && ok {
+			t.Errorf("json: limite enviado com zero: %s", msg)
+		}
+		if limite > 0 && v != float64(limite) {
+			t.Errorf("json: limite = %v, esperado %d", v, limite)
+		}
+
+		msg, err = ProtoProtocol.Codec.Encode(call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var req pb.Requisicao
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			t.Fatal(err)
+		}
+		s, ok := req.GetOperacao().GetParametros()["limite"]
+		if limite == 0 && ok {
+			t.Errorf("proto: limite enviado com zero: %q", s)
+		}
+		if limite > 0 && s != "50" {
+			t.Errorf("proto: limite = %q, esperado 50", s)
+		}
+
+		msg, err = StringProtocol.Codec.Encode(call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(msg), "limite="); got != (limite > 0) {
+			t.Errorf("string: %q", msg)
+		}
+	}
+}
+
+func TestOperacoesServerTruncates(t *testing.T) {
+	quietLog(t)
+	c := WithInterceptors(newTestClient(t, "json"), semPaginacao(DefaultPageSize, 75))
+	s := sessaoComOperacoes(t, c, 75)
+	n, err := contaOperacoes(s)
+	if err == nil {
+		t.Fatalf("Operacoes parou em %d operações sem erro", n)
+	}
+	if n != 0 {
+		t.Errorf("%d operações entregues antes do erro", n)
+	}
+}
+
+// Servidores em Python mandam o histórico do protobuf como str() da lista; um
+// apóstrofo dentro de um texto vem entre aspas duplas.
+func TestProtoHistoricoPythonRepr(t *testing.T) {
+	casos := []struct {
+		historico string
+		want      []OperacaoInfo
+	}{
+		{
+			`[{'operacao': 'echo', 'timestamp': '2024-05-01T12:00:00', 'sucesso': True}, ` +
+				`{'operacao': "d'água", 'timestamp': None, 'sucesso': False}]`,
+			[]OperacaoInfo{
+				{Comando: "echo", Timestamp: "2024-05-01T12:00:00", Sucesso: true},
+				{Comando: "d'água", Timestamp: "", Sucesso: false},
+			},
+		},
+		{
+			`[{"operacao": "soma", "timestamp": "2024-05-01T12:00:01", "sucesso": true}]`,
+			[]OperacaoInfo{{Comando: "soma", Timestamp: "2024-05-01T12:00:01", Sucesso: true}},
+		},
+		{`[]`, []OperacaoInfo{}},
+		{``, nil},
+	}
+	for _, caso := range casos {
+		msg, err := proto.Marshal(&pb.Resposta{Operacao: &pb.OperacaoResponse{
+			Sucesso:   true,
+			Resultado: map[string]string{"historico": caso.historico},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := ProtoProtocol.Codec.Decode(Call{Op: OpHistorico, Req: HistoricoRequest{Token: "t"}}, msg)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", caso.historico, err)
+		}
+		if got := res.(*HistoricoResponse).Operacoes; !reflect.DeepEqual(got, caso.want) {
+			t.Errorf("Decode(%q) = %#v, esperado %#v", caso.historico, got, caso.want)
+		}
+	}
+
+	for _, invalido := range []string{`[{'operacao': 'echo'`, `{'operacao': 'echo'}`, `['echo']`} {
+		msg, _ := proto.Marshal(&pb.Resposta{Operacao: &pb.OperacaoResponse{
+			Sucesso:   true,
+			Resultado: map[string]string{"historico": invalido},
+		}})
+		_, err := ProtoProtocol.Codec.Decode(Call{Op: OpHistorico, Req: HistoricoRequest{Token: "t"}}, msg)
+		if !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("Decode(%q) = %v, esperado ErrInvalidResponse", invalido, err)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+const (
+	OpConnect    = "connect"
+	OpDisconnect = "disconnect"
+	OpAuth       = "auth"
+	OpEcho       = "echo"
+	OpSoma       = "soma"
+	OpTimestamp  = "timestamp"
+	OpStatus     = "status"
+	OpHistorico  = "historico"
+	OpInfo       = "info"
+	OpLogout     = "logout"
+)
+
+type ConnectRequest struct {
+	Host string
+}
+
+type AuthRequest struct {
+	AlunoID string
+}
+
+type EchoRequest struct {
+	Token    string
+	Mensagem string
+}
+
+type SomaRequest struct {
+	Token   string
+	Numeros []string
+}
+
+type TimestampRequest struct {
+	Token string
+}
+
+type StatusRequest struct {
+	Token     string
+	Detalhado bool
+}
+
+type HistoricoRequest struct {
+	Token  string
+	Limite int
+	Offset int
+	Cursor string
+}
+
+type InfoRequest struct {
+	Token string
+	Tipo  string
+}
+
+type LogoutRequest struct {
+	Token string
+}
+
+// Invoker executa uma operação do Client. req é um dos tipos *Request deste
+// pacote (nil para disconnect) e a resposta é o ponteiro retornado pelo método
+// correspondente (nil para connect, disconnect e logout).
+type Invoker func(ctx context.Context, op string, req any) (any, error)
+
+type Interceptor func(ctx context.Context, op string, req any, next Invoker) (any, error)
+
+// InterceptedClient decora um Client aplicando uma cadeia de interceptores a
+// todas as chamadas. O primeiro interceptor da lista é o mais externo.
+type InterceptedClient struct {
+	inner   Client
+	invoker Invoker
+}
+
+func WithInterceptors(c Client, interceptors ...Interceptor) *InterceptedClient {
+	ic := &InterceptedClient{inner: c}
+	ic.invoker = ic.call
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		next, in := ic.invoker, interceptors[i]
+		ic.invoker = func(ctx context.Context, op string, req any) (any, error) {
+			return in(ctx, op, req, next)
+		}
+	}
+	return ic
+}
+
+func (c *InterceptedClient) Unwrap() Client {
+	return c.inner
+}
+
+func (c *InterceptedClient) call(ctx context.Context, op string, req any) (any, error) {
+	switch r := req.(type) {
+	case ConnectRequest:
+		return nil, c.inner.Connect(ctx, r.Host)
+	case nil:
+		if op == OpDisconnect {
+			return nil, c.inner.Disconnect()
+		}
+	case AuthRequest:
+		return c.inner.Auth(ctx, r.AlunoID)
+	case EchoRequest:
+		return c.inner.OpEcho(ctx, r.Token, r.Mensagem)
+	case SomaRequest:
+		return c.inner.OpSoma(ctx, r.Token, r.Numeros)
+	case TimestampRequest:
+		return c.inner.OpTimestamp(ctx, r.Token)
+	case StatusRequest:
+		return c.inner.OpStatus(ctx, r.Token, r.Detalhado)
+	case HistoricoRequest:
+		if r.Offset == 0 && r.Cursor == "" {
+			return c.inner.OpHistorico(ctx, r.Token, r.Limite)
+		}
+		return c.inner.OpHistoricoPagina(ctx, r.Token, Pagina{Limite: r.Limite, Offset: r.Offset, Cursor: r.Cursor})
+	case InfoRequest:
+		return c.inner.Info(ctx, r.Token, r.Tipo)
+	case LogoutRequest:
+		return nil, c.inner.Logout(ctx, r.Token)
+	}
+	return nil, newError(ErrInvalidRequest, nil, "interceptor: requisição inválida para a operação '%s': %T", op, req)
+}
+
+func invokeAs[T any](ctx context.Context, c *InterceptedClient, op string, req any) (T, error) {
+	var zero T
+	res, err := c.invoker(ctx, op, req)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := res.(T)
+	if !ok {
+		return zero, newError(ErrInvalidResponse, nil, "interceptor: resposta inesperada para a operação '%s': %T", op, res)
+	}
+	return v, nil
+}
+
+func (c *InterceptedClient) Connect(ctx context.Context, host string) error {
+	_, err := c.invoker(ctx, OpConnect, ConnectRequest{Host: host})
+	return err
+}
+
+func (c *InterceptedClient) Disconnect() error {
+	_, err := c.invoker(context.Background(), OpDisconnect, nil)
+	return err
+}
+
+func (c *InterceptedClient) Auth(ctx context.Context, alunoID string) (*AuthResponse, error) {
+	return invokeAs[*AuthResponse](ctx, c, OpAuth, AuthRequest{AlunoID: alunoID})
+}
+
+func (c *InterceptedClient) OpEcho(ctx context.Context, token, msg string) (*EchoResponse, error) {
+	return invokeAs[*EchoResponse](ctx, c, OpEcho, EchoRequest{Token: token, Mensagem: msg})
+}
+
+func (c *InterceptedClient) OpSoma(ctx context.Context, token string, numeros []string) (*SomaResponse, error) {
+	return invokeAs[*SomaResponse](ctx, c, OpSoma, SomaRequest{Token: token, Numeros: numeros})
+}
+
+func (c *InterceptedClient) OpTimestamp(ctx context.Context, token string) (*TimestampResponse, error) {
+	return invokeAs[*TimestampResponse](ctx, c, OpTimestamp, TimestampRequest{Token: token})
+}
+
+func (c *InterceptedClient) OpStatus(ctx context.Context, token string, detalhado bool) (*StatusResponse, error) {
+	return invokeAs[*StatusResponse](ctx, c, OpStatus, StatusRequest{Token: token, Detalhado: detalhado})
+}
+
+func (c *InterceptedClient) OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error) {
+	return invokeAs[*HistoricoResponse](ctx, c, OpHistorico, HistoricoRequest{Token: token, Limite: limite})
+}
+
+func (c *InterceptedClient) OpHistoricoPagina(ctx context.Context, token string, p Pagina) (*HistoricoResponse, error) {
+	return invokeAs[*HistoricoResponse](ctx, c, OpHistorico, HistoricoRequest{Token: token, Limite: p.Limite, Offset: p.Offset, Cursor: p.Cursor})
+}
+
+func (c *InterceptedClient) Info(ctx context.Context, token, tipo string) (*InfoResponse, error) {
+	return invokeAs[*InfoResponse](ctx, c, OpInfo, InfoRequest{Token: token, Tipo: tipo})
+}
+
+func (c *InterceptedClient) Logout(ctx context.Context, token string) error {
+	_, err := c.invoker(ctx, OpLogout, LogoutRequest{Token: token})
+	return err
+}
+
+func LoggingInterceptor(l *log.Logger) Interceptor {
+	if l == nil {
+		l = log.Default()
+	}
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		l.Printf(i18n.T("[%s] requisição: %+v"), op, req)
+		res, err := next(ctx, op, req)
+		if err != nil {
+			l.Printf(i18n.T("[%s] erro: %v"), op, err)
+		} else if res != nil {
+			l.Printf(i18n.T("[%s] resposta: %+v"), op, res)
+		}
+		return res, err
+	}
+}
+
+func TimingInterceptor(observe func(op string, d time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		inicio := time.Now()
+		res, err := next(ctx, op, req)
+		observe(op, time.Since(inicio), err)
+		return res, err
+	}
+}
+
+// IsRetryable considera passíveis de nova tentativa os erros de timeout e de
+// conexão.
+func IsRetryable(err error) bool {
+	switch errorKind(err) {
+	case "timeout", "conexao":
+		return true
+	}
+	return false
+}
+
+// IsIdempotent indica as operações que podem ser repetidas sem efeito
+// adicional no servidor. Soma e logout alteram o estado da sessão e ficam de
+// fora.
+func IsIdempotent(op string) bool {
+	switch op {
+	case OpConnect, OpAuth, OpEcho, OpTimestamp, OpStatus, OpHistorico, OpInfo:
+		return true
+	}
+	return false
+}
+
+// RetryIdempotent é o critério padrão de RetryInterceptor: erros de timeout ou
+// de conexão em operações idempotentes.
+func RetryIdempotent(op string, err error) bool {
+	return IsIdempotent(op) && IsRetryable(err)
+}
+
+// RetryInterceptor repete a operação até tentativas vezes quando
+// retryable(op, err) é verdadeiro (RetryIdempotent se nil), com espera
+// exponencial a partir de espera. Depois de uma falha a conexão não é mais
+// confiável (uma resposta atrasada dessincronizaria as mensagens), então cada
+// nova tentativa desconecta, reconecta ao mesmo host e, se já houve Auth,
+// autentica de novo o mesmo aluno. O token novo substitui o antigo nesta e nas
+// próximas chamadas.
+func RetryInterceptor(tentativas int, espera time.Duration, retryable func(op string, err error) bool) Interceptor {
+	if retryable == nil {
+		retryable = RetryIdempotent
+	}
+	var st retryState
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		req = st.atualiza(req)
+		var res any
+		var err error
+		for i := 0; i < max(tentativas, 1); i++ {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, fmt.Errorf("%w (%s)", ctx.Err(), i18n.T("última falha: %v", err))
+				case <-time.After(espera << (i - 1)):
+				}
+				if req, err = st.reconecta(ctx, op, req, next); err != nil {
+					if retryable(op, err) {
+						continue
+					}
+					return nil, err
+				}
+			}
+			res, err = next(ctx, op, req)
+			if err == nil {
+				st.sucesso(op, req, res)
+			}
+			if err == nil || op == OpDisconnect || !retryable(op, err) {
+				return res, err
+			}
+		}
+		return res, err
+	}
+}
+
+// retryState guarda o necessário para RetryInterceptor refazer a conexão e a
+// autenticação: o host, o aluno e os tokens substituídos por reautenticações.
+type retryState struct {
+	mu     sync.Mutex
+	host   string
+	aluno  string
+	token  string
+	tokens map[string]string
+}
+
+func (st *retryState) sucesso(op string, req, res any) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	switch op {
+	case OpConnect:
+		st.host = req.(ConnectRequest).Host
+	case OpAuth:
+		st.aluno = req.(AuthRequest).AlunoID
+		if r, ok := res.(*AuthResponse); ok {
+			st.token = r.Token
+		}
+	}
+}
+
+// atualiza troca um token já substituído pelo atual.
+func (st *retryState) atualiza(req any) any {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if novo, ok := st.tokens[requestToken(req)]; ok {
+		return withToken(req, novo)
+	}
+	return req
+}
+
+func (st *retryState) reconecta(ctx context.Context, op string, req any, next Invoker) (any, error) {
+	st.mu.Lock()
+	host, aluno, antigo := st.host, st.aluno, st.token
+	st.mu.Unlock()
+
+	next(ctx, OpDisconnect, nil)
+	if op == OpConnect {
+		return req, nil
+	}
+	if _, err := next(ctx, OpConnect, ConnectRequest{Host: host}); err != nil {
+		return req, err
+	}
+	if op == OpAuth || aluno == "" {
+		return req, nil
+	}
+	res, err := next(ctx, OpAuth, AuthRequest{AlunoID: aluno})
+	if err != nil {
+		return req, err
+	}
+	auth, ok := res.(*AuthResponse)
+	if !ok {
+		return req, newError(ErrInvalidResponse, nil, "interceptor: resposta inesperada para a operação '%s': %T", OpAuth, res)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.tokens == nil {
+		st.tokens = make(map[string]string)
+	}
+	for velho, atual := range st.tokens {
+		if atual == antigo {
+			st.tokens[velho] = auth.Token
+		}
+	}
+	if antigo != "" {
+		st.tokens[antigo] = auth.Token
+	}
+	if t := requestToken(req); t != "" {
+		st.tokens[t] = auth.Token
+	}
+	st.token = auth.Token
+	return withToken(req, auth.Token), nil
+}
+
+func withToken(req any, token string) any {
+	switch r := req.(type) {
+	case EchoRequest:
+		r.Token = token
+		return r
+	case SomaRequest:
+		r.Token = token
+		return r
+	case TimestampRequest:
+		r.Token = token
+		return r
+	case StatusRequest:
+		r.Token = token
+		return r
+	case HistoricoRequest:
+		r.Token = token
+		return r
+	case InfoRequest:
+		r.Token = token
+		return r
+	case LogoutRequest:
+		r.Token = token
+		return r
+	}
+	return req
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestRetryInterceptorReconnects(t *testing.T) {
+	var chamadas []string
+	falhas := 1
+	next := func(ctx context.Context, op string, req any) (any, error) {
+		chamadas = append(chamadas, fmt.Sprintf("%s %v", op, req))
+		switch op {
+		case OpAuth:
+			return &AuthResponse{Token: fmt.Sprintf("t%d", len(chamadas))}, nil
+		case OpEcho:
+			if falhas > 0 {
+				falhas--
+				return nil, io.EOF
+			}
+			return &EchoResponse{}, nil
+		}
+		return nil, nil
+	}
+
+	retry := RetryInterceptor(3, 0, nil)
+	ctx := context.Background()
+	retry(ctx, OpConnect, ConnectRequest{Host: "h"}, next)
+	auth, _ := retry(ctx, OpAuth, AuthRequest{AlunoID: "123"}, next)
+	token := auth.(*AuthResponse).Token
+	if _, err := retry(ctx, OpEcho, EchoRequest{Token: token, Mensagem: "a"}, next); err != nil {
+		t.Fatal(err)
+	}
+	// O token antigo continua aceito pelo chamador e é trocado pelo novo.
+	retry(ctx, OpTimestamp, TimestampRequest{Token: token}, next)
+
+	want := []string{
+		"connect {h}",
+		"auth {123}",
+		"echo {t2 a}",
+		"disconnect <nil>",
+		"connect {h}",
+		"auth {123}",
+		"echo {t6 a}",
+		"timestamp {t6}",
+	}
+	if !slices.Equal(chamadas, want) {
+		t.Errorf("chamadas:\n%q\nesperado:\n%q", chamadas, want)
+	}
+}
+
+func TestRetryInterceptorNonIdempotent(t *testing.T) {
+	for _, op := range []string{OpSoma, OpLogout} {
+		n := 0
+		next := func(ctx context.Context, op string, req any) (any, error) {
+			n++
+			return nil, io.EOF
+		}
+		RetryInterceptor(3, 0, nil)(context.Background(), op, LogoutRequest{}, next)
+		if n != 1 {
+			t.Errorf("%s: %d chamadas, esperado 1", op, n)
+		}
+	}
+}
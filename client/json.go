@@ -1,25 +1,43 @@
 package client
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
 )
 
+var JSONProtocol = Protocol{
+	Nome:      "json",
+	Porta:     "8081",
+	Codec:     &envelopeCodec{tag: "JSON", marshal: json.Marshal, unmarshal: json.Unmarshal},
+	NewFramer: NewJSONStreamFramer,
+}
+
+func NewJsonClient() *CodecClient {
+	return NewCodecClient(JSONProtocol)
+}
+
 type jsonOperationRequest struct {
-	Tipo       string `json:"tipo"`
-	Token      string `json:"token"`
-	Operacao   string `json:"operacao"`
-	Parametros any    `json:"parametros"`
-	Timestamp  string `json:"timestamp"`
+	Tipo         string            `json:"tipo"`
+	Token        string            `json:"token"`
+	Operacao     string            `json:"operacao"`
+	Parametros   any               `json:"parametros"`
+	Timestamp    string            `json:"timestamp"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	Aceita       string            `json:"aceita_codificacao,omitempty"`
 }
 type jsonAuthRequest struct {
-	Tipo      string `json:"tipo"`
-	AlunoID   string `json:"aluno_id"`
-	Timestamp string `json:"timestamp"`
+	Tipo         string            `json:"tipo"`
+	AlunoID      string            `json:"aluno_id"`
+	Timestamp    string            `json:"timestamp"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	Aceita       string            `json:"aceita_codificacao,omitempty"`
 }
 type jsonEchoParams struct {
 	Mensagem string `json:"mensagem"`
@@ -31,7 +49,9 @@ type jsonStatusParams struct {
 	Detalhado bool `json:"detalhado"`
 }
 type jsonHistoricoParams struct {
-	Limite int `json:"limite"`
+	Limite int    `json:"limite,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 type jsonBaseResponse struct {
@@ -61,289 +81,314 @@ type jsonOperationResponse struct {
 	Timestamp string         `json:"timestamp"`
 }
 
-type JsonClient struct {
-	baseClient
-	encoder *json.Encoder
-	decoder *json.Decoder
-}
-
-func NewJsonClient() *JsonClient {
-	return &JsonClient{}
-}
-
-func (c *JsonClient) Connect(ctx context.Context, host string) error {
-	if err := c.baseClient.Connect(ctx, host, "8081"); err != nil {
-		return err
-	}
-	c.encoder = json.NewEncoder(c.conn)
-	c.decoder = json.NewDecoder(c.conn)
-	return nil
+// envelopeCodec monta os envelopes do protocolo JSON (tipo, token, operacao,
+// parametros...). O mesmo envelope é serializado em JSON ou, com outro
+// marshal/unmarshal, nos formatos binários de wire.EnvelopeCodecs.
+type envelopeCodec struct {
+	tag       string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
 }
 
-func (c *JsonClient) sendAndReceive(ctx context.Context, request any, responseDest any) error {
-	if err := c.setDeadline(ctx); err != nil {
-		return err
-	}
-	debugBytes, _ := json.Marshal(request)
-	log.Printf("[DEBUG JSON] Enviando: %s\n", string(debugBytes))
-
-	if err := c.encoder.Encode(request); err != nil {
-		return fmt.Errorf("falha ao enviar JSON: %w", err)
-	}
-	if err := c.decoder.Decode(responseDest); err != nil {
-		return fmt.Errorf("falha ao ler/decodificar resposta JSON: %w", err)
+func (c *envelopeCodec) Encode(call Call) ([]byte, error) {
+	req, err := c.request(call)
+	if err != nil {
+		return nil, err
 	}
-	debugRespBytes, _ := json.Marshal(responseDest)
-	log.Printf("[DEBUG JSON] Recebido: %s\n", string(debugRespBytes))
-	return nil
+	debugBytes, _ := json.Marshal(req)
+	log.Print(i18n.T("[DEBUG %s] Enviando: %s", c.tag, string(debugBytes)))
+	return c.marshal(req)
 }
 
-func (c *JsonClient) Auth(ctx context.Context, alunoID string) (*AuthResponse, error) {
-	req := jsonAuthRequest{
-		Tipo:      "autenticar",
-		AlunoID:   alunoID,
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
-
-	var resp jsonAuthResponse
-	if err := c.sendAndReceive(ctx, req, &resp); err != nil {
-		return nil, err
+func (c *envelopeCodec) request(call Call) (any, error) {
+	ts := time.Now().Format(time.RFC3339)
+	op := func(token, nome string, params any) jsonOperationRequest {
+		return jsonOperationRequest{
+			Tipo:         "operacao",
+			Token:        token,
+			Operacao:     nome,
+			Parametros:   params,
+			Timestamp:    ts,
+			TraceContext: call.TraceContext,
+			Aceita:       call.AceitaCompressao,
+		}
 	}
-
-	if !resp.Sucesso {
-		if resp.Erro != "" {
-			return nil, fmt.Errorf("falha na autenticação: %s", resp.Erro)
+	tipo := func(tipo, token string) map[string]any {
+		req := map[string]any{
+			"tipo":      tipo,
+			"token":     token,
+			"timestamp": ts,
 		}
-		if resp.Mensagem != "" {
-			return nil, fmt.Errorf("falha na autenticação: %s", resp.Mensagem)
+		if call.TraceContext != nil {
+			req["trace_context"] = call.TraceContext
 		}
-		return nil, fmt.Errorf("falha na autenticação: (status não OK e sem mensagem de erro)")
-	}
-
-	return &AuthResponse{
-		Token:     resp.Token,
-		Nome:      resp.DadosAluno.Nome,
-		Matricula: alunoID,
-	}, nil
-}
-
-func (c *JsonClient) opRequestHelper(ctx context.Context, token, opName string, params any) (*jsonOperationResponse, error) {
-	req := jsonOperationRequest{
-		Tipo:       "operacao",
-		Token:      token,
-		Operacao:   opName,
-		Parametros: params,
-		Timestamp:  time.Now().Format(time.RFC3339),
-	}
-
-	var resp jsonOperationResponse
-	if err := c.sendAndReceive(ctx, req, &resp); err != nil {
-		return nil, err
+		if call.AceitaCompressao != "" {
+			req[wire.CampoAceitaCompressao] = call.AceitaCompressao
+		}
+		return req
 	}
 
-	if !resp.Sucesso {
-		if resp.Erro != "" {
-			return nil, fmt.Errorf("erro na operação '%s': %s", opName, resp.Erro)
+	switch r := call.Req.(type) {
+	case AuthRequest:
+		return jsonAuthRequest{
+			Tipo:         "autenticar",
+			AlunoID:      r.AlunoID,
+			Timestamp:    ts,
+			TraceContext: call.TraceContext,
+			Aceita:       call.AceitaCompressao,
+		}, nil
+	case EchoRequest:
+		return op(r.Token, "echo", jsonEchoParams{Mensagem: r.Mensagem}), nil
+	case SomaRequest:
+		var numsInt []int
+		for _, s := range r.Numeros {
+			n, err := strconv.Atoi(s)
+			if err == nil {
+				numsInt = append(numsInt, n)
+			} else {
+				f, err := strconv.ParseFloat(s, 64)
+				if err == nil {
+					numsInt = append(numsInt, int(f))
+				}
+			}
 		}
-		if resp.Mensagem != "" {
-			return nil, fmt.Errorf("erro na operação '%s': %s", opName, resp.Mensagem)
+		return op(r.Token, "soma", jsonSomaParams{Numeros: numsInt}), nil
+	case TimestampRequest:
+		return op(r.Token, "timestamp", make(map[string]any)), nil
+	case StatusRequest:
+		return op(r.Token, "status", jsonStatusParams{Detalhado: r.Detalhado}), nil
+	case HistoricoRequest:
+		return op(r.Token, "historico", jsonHistoricoParams{Limite: r.Limite, Offset: r.Offset, Cursor: r.Cursor}), nil
+	case InfoRequest:
+		if call.Alternativa {
+			return op(r.Token, "info", map[string]any{"tipo": r.Tipo}), nil
 		}
-		return nil, fmt.Errorf("erro na operação '%s': (status não OK e sem mensagem de erro)", opName)
+		return tipo("info", r.Token), nil
+	case LogoutRequest:
+		return tipo("logout", r.Token), nil
 	}
-
-	return &resp, nil
+	return nil, newError(ErrInvalidRequest, nil, "requisição inválida para a operação '%s': %T", call.Op, call.Req)
 }
 
-func (c *JsonClient) OpEcho(ctx context.Context, token, msg string) (*EchoResponse, error) {
-	params := jsonEchoParams{Mensagem: msg}
-	resp, err := c.opRequestHelper(ctx, token, "echo", params)
-	if err != nil {
-		return nil, err
+func (c *envelopeCodec) decode(msg []byte, dest any) error {
+	if err := c.unmarshal(msg, dest); err != nil {
+		return newError(ErrReceive, err, "falha ao ler/decodificar resposta %s", c.tag)
 	}
-
-	r := resp.Resultado
-	return &EchoResponse{
-		MensagemOriginal: r["mensagem_original"].(string),
-		Eco:              r["mensagem_eco"].(string),
-		Timestamp:        r["timestamp_servidor"].(string),
-		Tamanho:          int(r["tamanho_mensagem"].(float64)),
-		HashMD5:          r["hash_md5"].(string),
-	}, nil
+	if r, ok := dest.(*jsonOperationResponse); ok {
+		normalizeNumbers(r.Resultado)
+	}
+	debugRespBytes, _ := json.Marshal(dest)
+	log.Print(i18n.T("[DEBUG %s] Recebido: %s", c.tag, string(debugRespBytes)))
+	return nil
 }
 
-func (c *JsonClient) OpSoma(ctx context.Context, token string, numeros []string) (*SomaResponse, error) {
-	var numsInt []int
-	for _, s := range numeros {
-		n, err := strconv.Atoi(s)
-		if err == nil {
-			numsInt = append(numsInt, n)
-		} else {
-			f, err := strconv.ParseFloat(s, 64)
-			if err == nil {
-				numsInt = append(numsInt, int(f))
+func (c *envelopeCodec) Decode(call Call, msg []byte) (any, error) {
+	switch call.Op {
+	case OpAuth:
+		var resp jsonAuthResponse
+		if err := c.decode(msg, &resp); err != nil {
+			return nil, err
+		}
+		if !resp.Sucesso {
+			if resp.Erro != "" {
+				return nil, newError(ErrAuth, &ServerError{Operacao: OpAuth, Mensagem: resp.Erro}, "")
+			}
+			if resp.Mensagem != "" {
+				return nil, newError(ErrAuth, &ServerError{Operacao: OpAuth, Mensagem: resp.Mensagem}, "")
 			}
+			return nil, newError(ErrAuth, nil, "falha na autenticação: (status não OK e sem mensagem de erro)")
 		}
+		return &AuthResponse{
+			Token:     resp.Token,
+			Nome:      resp.DadosAluno.Nome,
+			Matricula: call.Req.(AuthRequest).AlunoID,
+		}, nil
+
+	case OpLogout:
+		var resp jsonBaseResponse
+		if err := c.decode(msg, &resp); err != nil {
+			return nil, err
+		}
+		if !resp.Sucesso {
+			if resp.Erro != "" {
+				return nil, fmt.Errorf("%s: %w", i18n.T("falha no logout"), &ServerError{Operacao: OpLogout, Mensagem: resp.Erro})
+			}
+			return nil, newError(ErrInvalidResponse, nil, "falha no logout: (status não OK e sem mensagem de erro)")
+		}
+		return nil, nil
 	}
 
-	params := jsonSomaParams{Numeros: numsInt}
-	resp, err := c.opRequestHelper(ctx, token, "soma", params)
-	if err != nil {
-		return nil, err
-	}
-
-	r := resp.Resultado
-	return &SomaResponse{
-		Soma:               r["soma"].(float64),
-		Media:              r["media"].(float64),
-		Maximo:             r["maximo"].(float64),
-		Minimo:             r["minimo"].(float64),
-		NumerosProcessados: int(r["quantidade"].(float64)),
-	}, nil
-}
-
-func (c *JsonClient) OpTimestamp(ctx context.Context, token string) (*TimestampResponse, error) {
-	params := make(map[string]any)
-	resp, err := c.opRequestHelper(ctx, token, "timestamp", params)
-	if err != nil {
-		return nil, err
-	}
-
-	r := resp.Resultado
-	return &TimestampResponse{
-		TimestampFormatado:   r["timestamp_formatado"].(string),
-		Timezone:             "N/A",
-		InformacoesTemporais: r["timestamp_iso"].(string),
-	}, nil
-}
-
-func (c *JsonClient) OpStatus(ctx context.Context, token string, detalhado bool) (*StatusResponse, error) {
-	params := jsonStatusParams{Detalhado: detalhado}
-	resp, err := c.opRequestHelper(ctx, token, "status", params)
-	if err != nil {
+	var resp jsonOperationResponse
+	if err := c.decode(msg, &resp); err != nil {
 		return nil, err
 	}
-
-	r := resp.Resultado
-	statsMap, _ := r["estatisticas_banco"].(map[string]any)
-
-	return &StatusResponse{
-		Status:               r["status"].(string),
-		OperacoesProcessadas: int(r["operacoes_processadas"].(float64)),
-		Estatisticas:         statsMap,
-	}, nil
-}
-
-func (c *JsonClient) OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error) {
-	params := jsonHistoricoParams{Limite: limite}
-	resp, err := c.opRequestHelper(ctx, token, "historico", params)
-	if err != nil {
-		return nil, err
+	// Info pelo 'tipo' não informa sucesso; só a alternativa é conferida.
+	if call.Op != OpInfo || call.Alternativa {
+		if err := jsonOpError(call.Op, &resp); err != nil {
+			return nil, err
+		}
 	}
-
 	r := resp.Resultado
-	var operacoes []OperacaoInfo
 
-	if ops, ok := r["historico"].([]any); ok {
-		for _, opAny := range ops {
-			if opMap, ok := opAny.(map[string]any); ok {
-				var opNome, opTs string
-				var opSuc bool
-				if v, ok := opMap["operacao"].(string); ok {
-					opNome = v
-				}
-				if v, ok := opMap["timestamp"].(string); ok {
-					opTs = v
+	switch call.Op {
+	case OpEcho:
+		return &EchoResponse{
+			MensagemOriginal: r["mensagem_original"].(string),
+			Eco:              r["mensagem_eco"].(string),
+			Timestamp:        r["timestamp_servidor"].(string),
+			Tamanho:          int(r["tamanho_mensagem"].(float64)),
+			HashMD5:          r["hash_md5"].(string),
+		}, nil
+
+	case OpSoma:
+		return &SomaResponse{
+			Soma:               r["soma"].(float64),
+			Media:              r["media"].(float64),
+			Maximo:             r["maximo"].(float64),
+			Minimo:             r["minimo"].(float64),
+			NumerosProcessados: int(r["quantidade"].(float64)),
+		}, nil
+
+	case OpTimestamp:
+		iso, _ := r["timestamp_iso"].(string)
+		formatado, _ := r["timestamp_formatado"].(string)
+		tz, _ := r["timezone"].(string)
+		return newTimestampResponse(iso, formatado, tz, call.Local)
+
+	case OpStatus:
+		statsMap, _ := r["estatisticas_banco"].(map[string]any)
+		return &StatusResponse{
+			Status:               r["status"].(string),
+			OperacoesProcessadas: int(r["operacoes_processadas"].(float64)),
+			Estatisticas:         parseEstatisticas(statsMap),
+		}, nil
+
+	case OpHistorico:
+		var operacoes []OperacaoInfo
+		if ops, ok := r["historico"].([]any); ok {
+			for _, opAny := range ops {
+				if opMap, ok := opAny.(map[string]any); ok {
+					var opNome, opTs string
+					var opSuc bool
+					if v, ok := opMap["operacao"].(string); ok {
+						opNome = v
+					}
+					if v, ok := opMap["timestamp"].(string); ok {
+						opTs = v
+					}
+					if v, ok := opMap["sucesso"].(bool); ok {
+						opSuc = v
+					}
+
+					operacoes = append(operacoes, OperacaoInfo{
+						Comando:   opNome,
+						Timestamp: opTs,
+						Sucesso:   opSuc,
+					})
 				}
-				if v, ok := opMap["sucesso"].(bool); ok {
-					opSuc = v
-				}
-
-				operacoes = append(operacoes, OperacaoInfo{
-					Comando:   opNome,
-					Timestamp: opTs,
-					Sucesso:   opSuc,
-				})
 			}
 		}
+		statsMap, _ := r["estatisticas"].(map[string]any)
+		cursor, _ := r["proximo_cursor"].(string)
+		return &HistoricoResponse{
+			Operacoes:     operacoes,
+			Estatisticas:  parseEstatisticas(statsMap),
+			ProximoCursor: cursor,
+		}, nil
+
+	case OpInfo:
+		var desc, proto string
+		if d, ok := r["nome"].(string); ok {
+			desc = d
+		}
+		if p, ok := r["versao"].(string); ok {
+			proto = p
+		}
+		// Capacidades chegam como texto separado por vírgulas, como no
+		// protobuf, ou como lista.
+		var capacidades []string
+		switch c := r["capacidades"].(type) {
+		case string:
+			if c != "" {
+				capacidades = strings.Split(c, ",")
+			}
+		case []any:
+			for _, v := range c {
+				capacidades = append(capacidades, fmt.Sprint(v))
+			}
+		}
+		return &InfoResponse{
+			DescricaoServidor: desc,
+			ProtocoloAtivo:    proto,
+			Capacidades:       capacidades,
+		}, nil
 	}
-
-	statsMap, _ := r["estatisticas"].(map[string]any)
-
-	return &HistoricoResponse{
-		Operacoes:    operacoes,
-		Estatisticas: statsMap,
-	}, nil
+	return nil, newError(ErrUnsupported, nil, "operação '%s' não suportada", call.Op)
 }
 
-func (c *JsonClient) Info(ctx context.Context, token, tipo string) (*InfoResponse, error) {
-	req := map[string]any{
-		"tipo":      "info",
-		"token":     token,
-		"timestamp": time.Now().Format(time.RFC3339),
-	}
-
-	var resp jsonOperationResponse
-	if err := c.sendAndReceive(ctx, req, &resp); err != nil {
-		log.Printf("Falha no 'tipo'=\"info\", tentando 'operacao'=\"info\"...")
-		return c.InfoAsOperation(ctx, token, tipo)
-	}
-
-	r := resp.Resultado
-	var desc, proto string
-
-	if d, ok := r["nome"].(string); ok {
-		desc = d
+// Fallback reenvia o Info como operação ('operacao'="info") quando o servidor
+// não atende o 'tipo'="info".
+func (c *envelopeCodec) Fallback(call Call, err error) (*Call, any, bool) {
+	if call.Op != OpInfo || call.Alternativa {
+		return nil, nil, false
 	}
-	if p, ok := r["versao"].(string); ok {
-		proto = p
-	}
-
-	return &InfoResponse{
-		DescricaoServidor: desc,
-		ProtocoloAtivo:    proto,
-	}, nil
+	log.Print(i18n.T("Falha no 'tipo'=\"info\", tentando 'operacao'=\"info\"..."))
+	call.Alternativa = true
+	return &call, nil, true
 }
 
-func (c *JsonClient) InfoAsOperation(ctx context.Context, token, tipo string) (*InfoResponse, error) {
-	params := map[string]any{"tipo": tipo}
-	resp, err := c.opRequestHelper(ctx, token, "info", params)
-	if err != nil {
-		return nil, err
+func jsonOpError(opName string, resp *jsonOperationResponse) error {
+	if resp.Sucesso {
+		return nil
 	}
-
-	r := resp.Resultado
-	var desc, proto string
-	if d, ok := r["nome"].(string); ok {
-		desc = d
+	if resp.Erro != "" {
+		return fmt.Errorf("%s: %w", i18n.T("erro na operação '%s'", opName), &ServerError{Operacao: opName, Mensagem: resp.Erro})
 	}
-	if p, ok := r["versao"].(string); ok {
-		proto = p
+	if resp.Mensagem != "" {
+		return fmt.Errorf("%s: %w", i18n.T("erro na operação '%s'", opName), &ServerError{Operacao: opName, Mensagem: resp.Mensagem})
 	}
-
-	return &InfoResponse{
-		DescricaoServidor: desc,
-		ProtocoloAtivo:    proto,
-	}, nil
+	return newError(ErrInvalidResponse, nil, "erro na operação '%s': (status não OK e sem mensagem de erro)", opName)
 }
 
-func (c *JsonClient) Logout(ctx context.Context, token string) error {
-	req := map[string]any{
-		"tipo":      "logout",
-		"token":     token,
-		"timestamp": time.Now().Format(time.RFC3339),
-	}
-
-	var resp jsonBaseResponse
-	if err := c.sendAndReceive(ctx, req, &resp); err != nil {
-		return err
+// normalizeNumbers converte para float64 os números decodificados de formatos
+// binários, que distinguem inteiros de ponto flutuante, para que o resultado
+// tenha os mesmos tipos de um documento JSON.
+func normalizeNumbers(m map[string]any) {
+	for k, v := range m {
+		m[k] = normalizeNumber(v)
 	}
+}
 
-	if !resp.Sucesso {
-		if resp.Erro != "" {
-			return fmt.Errorf("falha no logout: %s", resp.Erro)
+func normalizeNumber(v any) any {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case map[string]any:
+		normalizeNumbers(v)
+	case []any:
+		for i := range v {
+			v[i] = normalizeNumber(v[i])
 		}
-		return fmt.Errorf("falha no logout: (status não OK e sem mensagem de erro)")
 	}
-
-	return nil
+	return v
 }
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+const DefaultMaxFrameSize = 16 << 20
+
+// SetMaxFrameSize define o tamanho máximo, em bytes, de uma resposta do
+// servidor: payload protobuf, linha do String ou documento JSON. Zero ou
+// negativo restaura DefaultMaxFrameSize.
+func (c *baseClient) SetMaxFrameSize(n int64) {
+	c.maxFrameSize = n
+}
+
+func (c *baseClient) frameLimit() int64 {
+	if c.maxFrameSize <= 0 {
+		return DefaultMaxFrameSize
+	}
+	return c.maxFrameSize
+}
+
+// readLengthPrefixed lê um frame com cabeçalho de 4 bytes (BigEndian), ou o
+// cabeçalho estendido de wire.AppendProtoFrame, e devolve o payload já
+// descomprimido junto com o algoritmo anunciado pelo servidor. O payload é
+// lido aos poucos, sem alocar de antemão o tamanho anunciado.
+func readLengthPrefixed(r io.Reader, limite int64, protocolo string) ([]byte, string, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:4]); err != nil {
+		return nil, "", err
+	}
+	h := wire.ParseProtoHeader(hdr[:])
+	if h.Estendido {
+		if _, err := io.ReadFull(r, hdr[4:]); err != nil {
+			return nil, "", err
+		}
+	}
+	if h.Tamanho > limite {
+		return nil, "", &FrameTooLargeError{Protocolo: protocolo, Tamanho: h.Tamanho, Limite: limite}
+	}
+
+	buf := bytes.NewBuffer(hdr[:h.Len():h.Len()])
+	n, err := buf.ReadFrom(io.LimitReader(r, h.Tamanho))
+	if err != nil {
+		return nil, "", err
+	}
+	if n < h.Tamanho {
+		return nil, "", io.ErrUnexpectedEOF
+	}
+	payload, alg, err := wire.ProtoPayload(buf.Bytes(), limite)
+	if err != nil {
+		return nil, "", decompressError(err, protocolo, limite)
+	}
+	return payload, alg, nil
+}
+
+// readLine lê até '\n' (inclusive) recusando linhas maiores que limite.
+func readLine(r *bufio.Reader, limite int64, protocolo string) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if int64(len(line)+len(chunk)) > limite {
+			return "", &FrameTooLargeError{Protocolo: protocolo, Tamanho: -1, Limite: limite}
+		}
+		line = append(line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return string(line), err
+		}
+		return string(line), nil
+	}
+}
+
+// readJSONDoc lê o próximo documento JSON (objeto ou lista) recusando os que
+// passam de limite bytes; os espaços entre documentos não contam. Os bytes
+// seguintes ao documento ficam no reader para a próxima leitura.
+func readJSONDoc(r *bufio.Reader, limite int64, protocolo string) ([]byte, error) {
+	var doc []byte
+	depth := 0
+	inString, escaped := false, false
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(doc) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if len(doc) == 0 {
+			if b == ' ' || b == '\n' || b == '\r' || b == '\t' {
+				continue
+			}
+			if b != '{' && b != '[' {
+				// Mesmo erro que o json.Decoder daria.
+				var v any
+				return nil, json.Unmarshal([]byte{b}, &v)
+			}
+		}
+		if int64(len(doc)) >= limite {
+			return nil, &FrameTooLargeError{Protocolo: protocolo, Tamanho: -1, Limite: limite}
+		}
+		doc = append(doc, b)
+
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && b == '\\':
+			escaped = true
+		case b == '"':
+			inString = !inString
+		case inString:
+		case b == '{' || b == '[':
+			depth++
+		case b == '}' || b == ']':
+			depth--
+		}
+		if depth == 0 {
+			if !json.Valid(doc) {
+				var v any
+				return nil, json.Unmarshal(doc, &v)
+			}
+			return doc, nil
+		}
+	}
+}
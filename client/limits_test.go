@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+func assertFrameTooLarge(t *testing.T, err error, protocolo string, tamanho, limite int64) {
+	t.Helper()
+	var fe *FrameTooLargeError
+	if !errors.As(err, &fe) {
+		t.Fatalf("erro = %v (%T), esperado *FrameTooLargeError", err, err)
+	}
+	if fe.Protocolo != protocolo || fe.Tamanho != tamanho || fe.Limite != limite {
+		t.Errorf("FrameTooLargeError = %+v, esperado {Protocolo:%s Tamanho:%d Limite:%d}", *fe, protocolo, tamanho, limite)
+	}
+}
+
+func TestReadLengthPrefixedOversizedHeader(t *testing.T) {
+	// O cabeçalho anuncia 1 GiB sem payload: a recusa vem antes de qualquer
+	// leitura ou alocação do corpo.
+	hdr := binary.BigEndian.AppendUint32(nil, 1<<30)
+	_, _, err := readLengthPrefixed(bytes.NewReader(hdr), 1024, "proto")
+	assertFrameTooLarge(t, err, "proto", 1<<30, 1024)
+}
+
+func TestReadLengthPrefixedWithinLimit(t *testing.T) {
+	frame, _ := wire.AppendProtoFrame(nil, []byte("abc"), "")
+	payload, alg, err := readLengthPrefixed(bytes.NewReader(frame), 3, "proto")
+	if err != nil || string(payload) != "abc" || alg != "" {
+		t.Fatalf("readLengthPrefixed = %q, %q, %v", payload, alg, err)
+	}
+}
+
+func TestReadLengthPrefixedDecompressionLimit(t *testing.T) {
+	// O frame comprimido cabe no limite, mas o conteúdo descomprimido não.
+	frame, err := wire.AppendProtoFrame(nil, bytes.Repeat([]byte("a"), 64<<10), "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = readLengthPrefixed(bytes.NewReader(frame), 4096, "proto")
+	assertFrameTooLarge(t, err, "proto", -1, 4096)
+}
+
+func TestReadLineOversized(t *testing.T) {
+	// Linha maior que o buffer do bufio.Reader, lida em vários pedaços.
+	linha := strings.Repeat("x", 10000) + "\n"
+	r := bufio.NewReaderSize(strings.NewReader(linha), 16)
+	_, err := readLine(r, 4096, "string")
+	assertFrameTooLarge(t, err, "string", -1, 4096)
+
+	r = bufio.NewReaderSize(strings.NewReader(linha), 16)
+	got, err := readLine(r, int64(len(linha)), "string")
+	if err != nil || got != linha {
+		t.Fatalf("readLine no limite = %d bytes, %v", len(got), err)
+	}
+}
+
+func TestJSONFramerOversizedDocument(t *testing.T) {
+	doc := `{"mensagem":"` + strings.Repeat("x", 10000) + `"}`
+	f := NewJSONStreamFramer(&rwBuffer{Reader: strings.NewReader(doc)}, "json")
+	_, _, err := f.ReadFrame(4096)
+	assertFrameTooLarge(t, err, "json", -1, 4096)
+}
+
+// O limite vale para cada documento, mesmo quando parte dele já foi lida junto
+// com o anterior.
+func TestJSONFramerExactLimit(t *testing.T) {
+	const limite = 100
+	primeiro := `{"a":"` + strings.Repeat("x", 80) + `"}` // 88 bytes
+	noLimite := `{"b":"` + strings.Repeat("y", limite-8) + `"}`
+	acima := `{"c":"` + strings.Repeat("z", limite-7) + `"}`
+	stream := primeiro + "\n" + noLimite + "\n  " + acima + "\n"
+
+	f := NewJSONStreamFramer(&rwBuffer{Reader: strings.NewReader(stream)}, "json")
+	for _, want := range []string{primeiro, noLimite} {
+		msg, _, err := f.ReadFrame(limite)
+		if err != nil || string(msg) != want {
+			t.Fatalf("ReadFrame = %d bytes, %v; quer %d bytes", len(msg), err, len(want))
+		}
+	}
+	_, _, err := f.ReadFrame(limite)
+	assertFrameTooLarge(t, err, "json", -1, limite)
+}
+
+func TestJSONFramerInvalidDocument(t *testing.T) {
+	for _, stream := range []string{`oi`, `{"a" 1}`, `{"a":`} {
+		f := NewJSONStreamFramer(&rwBuffer{Reader: strings.NewReader(stream)}, "json")
+		if msg, _, err := f.ReadFrame(4096); err == nil {
+			t.Errorf("ReadFrame(%q) = %q, quer erro", stream, msg)
+		}
+	}
+	// Chaves e aspas dentro de textos não encerram o documento.
+	doc := `{"a":"}\"{","b":[1,{"c":"]"}]}`
+	f := NewJSONStreamFramer(&rwBuffer{Reader: strings.NewReader(doc + doc)}, "json")
+	for range 2 {
+		if msg, _, err := f.ReadFrame(4096); err != nil || string(msg) != doc {
+			t.Fatalf("ReadFrame = %q, %v; quer %q", msg, err, doc)
+		}
+	}
+	if _, _, err := f.ReadFrame(4096); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadFrame no fim = %v, quer io.EOF", err)
+	}
+}
+
+// rwBuffer serve um fluxo fixo para os framers; as escritas são descartadas.
+type rwBuffer struct {
+	*strings.Reader
+}
+
+func (rwBuffer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
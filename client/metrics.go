@@ -0,0 +1,252 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exchange descreve uma troca requisição/resposta observada em sendAndReceive.
+// Erro contém o tipo do erro ("timeout", "conexao", "codificacao", "servidor"...)
+// e fica vazio em caso de sucesso.
+type Exchange struct {
+	Protocolo      string
+	Operacao       string
+	Duracao        time.Duration
+	BytesEnviados  int64
+	BytesRecebidos int64
+	Erro           string
+}
+
+type MetricsHook interface {
+	ObserveExchange(e Exchange)
+}
+
+func errorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	var srvErr *ServerError
+	var sizeErr *FrameTooLargeError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &srvErr):
+		return "servidor"
+	case errors.As(err, &sizeErr):
+		return "tamanho"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "cancelado"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed), errors.As(err, &netErr):
+		return "conexao"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "codificacao"
+	default:
+		return "outro"
+	}
+}
+
+type countingConn struct {
+	net.Conn
+	enviados  atomic.Int64
+	recebidos atomic.Int64
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.recebidos.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.enviados.Add(int64(n))
+	return n, err
+}
+
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricKey struct {
+	protocolo string
+	operacao  string
+}
+
+type opSeries struct {
+	total     uint64
+	erros     map[string]uint64
+	enviados  int64
+	recebidos int64
+	buckets   []uint64
+	soma      float64
+}
+
+// Metrics é um MetricsHook em memória que expõe os dados no formato texto do
+// Prometheus através de ServeHTTP.
+type Metrics struct {
+	mu        sync.Mutex
+	series    map[metricKey]*opSeries
+	circuitos map[string]CircuitState
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		series:    make(map[metricKey]*opSeries),
+		circuitos: make(map[string]CircuitState),
+	}
+}
+
+// ObserveCircuitState registra o estado do circuit breaker de uma operação;
+// pode ser usado diretamente como CircuitBreaker.OnStateChange.
+func (m *Metrics) ObserveCircuitState(op string, estado CircuitState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.circuitos[op] = estado
+}
+
+func (m *Metrics) ObserveExchange(e Exchange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := metricKey{e.Protocolo, e.Operacao}
+	s, ok := m.series[k]
+	if !ok {
+		s = &opSeries{erros: make(map[string]uint64), buckets: make([]uint64, len(latencyBuckets))}
+		m.series[k] = s
+	}
+
+	s.total++
+	if e.Erro != "" {
+		s.erros[e.Erro]++
+	}
+	s.enviados += e.BytesEnviados
+	s.recebidos += e.BytesRecebidos
+
+	seg := e.Duracao.Seconds()
+	s.soma += seg
+	for i, b := range latencyBuckets {
+		if seg <= b {
+			s.buckets[i]++
+		}
+	}
+}
+
+// Bytes devolve o total de bytes enviados e recebidos em todas as operações.
+func (m *Metrics) Bytes() (enviados, recebidos int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.series {
+		enviados += s.enviados
+		recebidos += s.recebidos
+	}
+	return enviados, recebidos
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]metricKey, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b metricKey) int {
+		return cmp.Or(cmp.Compare(a.protocolo, b.protocolo), cmp.Compare(a.operacao, b.operacao))
+	})
+
+	cw := &countingWriter{w: w}
+	labels := func(k metricKey) string {
+		return fmt.Sprintf(`protocol=%q,operation=%q`, k.protocolo, k.operacao)
+	}
+
+	fmt.Fprintln(cw, "# HELP sd_client_requests_total Total de requisições enviadas ao servidor.")
+	fmt.Fprintln(cw, "# TYPE sd_client_requests_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(cw, "sd_client_requests_total{%s} %d\n", labels(k), m.series[k].total)
+	}
+
+	fmt.Fprintln(cw, "# HELP sd_client_errors_total Total de requisições com erro, por tipo.")
+	fmt.Fprintln(cw, "# TYPE sd_client_errors_total counter")
+	for _, k := range keys {
+		s := m.series[k]
+		tipos := make([]string, 0, len(s.erros))
+		for t := range s.erros {
+			tipos = append(tipos, t)
+		}
+		slices.Sort(tipos)
+		for _, t := range tipos {
+			fmt.Fprintf(cw, "sd_client_errors_total{%s,type=%q} %d\n", labels(k), t, s.erros[t])
+		}
+	}
+
+	fmt.Fprintln(cw, "# HELP sd_client_request_duration_seconds Latência das requisições.")
+	fmt.Fprintln(cw, "# TYPE sd_client_request_duration_seconds histogram")
+	for _, k := range keys {
+		s := m.series[k]
+		for i, b := range latencyBuckets {
+			fmt.Fprintf(cw, "sd_client_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels(k), b, s.buckets[i])
+		}
+		fmt.Fprintf(cw, "sd_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(k), s.total)
+		fmt.Fprintf(cw, "sd_client_request_duration_seconds_sum{%s} %g\n", labels(k), s.soma)
+		fmt.Fprintf(cw, "sd_client_request_duration_seconds_count{%s} %d\n", labels(k), s.total)
+	}
+
+	fmt.Fprintln(cw, "# HELP sd_client_bytes_sent_total Bytes enviados ao servidor.")
+	fmt.Fprintln(cw, "# TYPE sd_client_bytes_sent_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(cw, "sd_client_bytes_sent_total{%s} %d\n", labels(k), m.series[k].enviados)
+	}
+
+	fmt.Fprintln(cw, "# HELP sd_client_bytes_received_total Bytes recebidos do servidor.")
+	fmt.Fprintln(cw, "# TYPE sd_client_bytes_received_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(cw, "sd_client_bytes_received_total{%s} %d\n", labels(k), m.series[k].recebidos)
+	}
+
+	if len(m.circuitos) > 0 {
+		ops := make([]string, 0, len(m.circuitos))
+		for op := range m.circuitos {
+			ops = append(ops, op)
+		}
+		slices.Sort(ops)
+		fmt.Fprintln(cw, "# HELP sd_client_circuit_state Estado do circuit breaker (0=fechado, 1=aberto, 2=semi-aberto).")
+		fmt.Fprintln(cw, "# TYPE sd_client_circuit_state gauge")
+		for _, op := range ops {
+			fmt.Fprintf(cw, "sd_client_circuit_state{operation=%q} %d\n", op, m.circuitos[op])
+		}
+	}
+
+	return cw.n, cw.err
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
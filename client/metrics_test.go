@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorKind(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{&ServerError{Mensagem: "token inválido"}, "servidor"},
+		{fmt.Errorf("echo: %w", &ServerError{}), "servidor"},
+		{&FrameTooLargeError{Limite: 10}, "tamanho"},
+		{context.DeadlineExceeded, "timeout"},
+		{&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, "timeout"},
+		{context.Canceled, "cancelado"},
+		{io.EOF, "conexao"},
+		{fmt.Errorf("ler: %w", io.ErrUnexpectedEOF), "conexao"},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "conexao"},
+		{syntaxErr, "codificacao"},
+		{&json.UnmarshalTypeError{}, "codificacao"},
+		{errors.New("qualquer"), "outro"},
+	}
+	for _, tt := range tests {
+		if got := errorKind(tt.err); got != tt.want {
+			t.Errorf("errorKind(%v) = %q, quer %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMetricsExposition(t *testing.T) {
+	m := NewMetrics()
+	// Os limites dos buckets são inclusivos: 5ms entra em le="0.005".
+	for _, e := range []Exchange{
+		{Protocolo: "json", Operacao: "echo", Duracao: 5 * time.Millisecond, BytesEnviados: 10, BytesRecebidos: 20},
+		{Protocolo: "json", Operacao: "echo", Duracao: 30 * time.Millisecond, BytesEnviados: 10, BytesRecebidos: 20},
+		{Protocolo: "json", Operacao: "echo", Duracao: 20 * time.Second, Erro: "timeout"},
+		{Protocolo: "cbor", Operacao: "soma", Duracao: time.Millisecond, Erro: "servidor"},
+	} {
+		m.ObserveExchange(e)
+	}
+	m.ObserveCircuitState(OpEcho, CircuitOpen)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	out := rec.Body.String()
+	echo := `protocol="json",operation="echo"`
+	for _, linha := range []string{
+		"# TYPE sd_client_requests_total counter",
+		`sd_client_requests_total{` + echo + `} 3`,
+		`sd_client_requests_total{protocol="cbor",operation="soma"} 1`,
+		`sd_client_errors_total{` + echo + `,type="timeout"} 1`,
+		`sd_client_errors_total{protocol="cbor",operation="soma",type="servidor"} 1`,
+		"# TYPE sd_client_request_duration_seconds histogram",
+		`sd_client_request_duration_seconds_bucket{` + echo + `,le="0.005"} 1`,
+		`sd_client_request_duration_seconds_bucket{` + echo + `,le="0.025"} 1`,
+		`sd_client_request_duration_seconds_bucket{` + echo + `,le="0.05"} 2`,
+		`sd_client_request_duration_seconds_bucket{` + echo + `,le="10"} 2`,
+		`sd_client_request_duration_seconds_bucket{` + echo + `,le="+Inf"} 3`,
+		`sd_client_request_duration_seconds_sum{` + echo + `} 20.035`,
+		`sd_client_request_duration_seconds_count{` + echo + `} 3`,
+		`sd_client_bytes_sent_total{` + echo + `} 20`,
+		`sd_client_bytes_received_total{` + echo + `} 40`,
+		`sd_client_circuit_state{operation="echo"} 1`,
+	} {
+		if !strings.Contains(out, linha+"\n") {
+			t.Errorf("falta a linha %q em:\n%s", linha, out)
+		}
+	}
+	// As séries saem ordenadas por protocolo e operação.
+	if strings.Index(out, `protocol="cbor"`) > strings.Index(out, `protocol="json"`) {
+		t.Errorf("séries fora de ordem:\n%s", out)
+	}
+	if enviados, recebidos := m.Bytes(); enviados != 20 || recebidos != 40 {
+		t.Errorf("Bytes() = %d, %d; quer 20, 40", enviados, recebidos)
+	}
+}
+
+func TestMetricsFromClient(t *testing.T) {
+	quietLog(t)
+	c := newTestClient(t, "json")
+	m := NewMetrics()
+	c.SetMetrics(m)
+	ctx := context.Background()
+	auth, err := c.Auth(ctx, "520402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.OpEcho(ctx, auth.Token, "oi")
+	c.OpEcho(ctx, "token-invalido", "oi")
+
+	var b strings.Builder
+	m.WriteTo(&b)
+	out := b.String()
+	for _, linha := range []string{
+		`sd_client_requests_total{protocol="json",operation="auth"} 1`,
+		`sd_client_requests_total{protocol="json",operation="echo"} 2`,
+		`sd_client_errors_total{protocol="json",operation="echo",type="servidor"} 1`,
+	} {
+		if !strings.Contains(out, linha+"\n") {
+			t.Errorf("falta a linha %q em:\n%s", linha, out)
+		}
+	}
+	if enviados, recebidos := m.Bytes(); enviados == 0 || recebidos == 0 {
+		t.Errorf("Bytes() = %d, %d; quer bytes contados", enviados, recebidos)
+	}
+}
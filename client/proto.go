@@ -1,69 +1,78 @@
 package client
 
 import (
-	"context"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"io"
+	"log"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
 	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
 
 	"google.golang.org/protobuf/proto"
 )
 
-type ProtoClient struct {
-	baseClient
-}
-
-func NewProtoClient() *ProtoClient {
-	return &ProtoClient{}
-}
+var ProtoProtocol = Protocol{Nome: "proto", Porta: "8082", Codec: protoCodec{}, NewFramer: NewLengthPrefixFramer}
 
-func (c *ProtoClient) Connect(ctx context.Context, host string) error {
-	return c.baseClient.Connect(ctx, host, "8082")
+func NewProtoClient() *CodecClient {
+	return NewCodecClient(ProtoProtocol)
 }
 
-func (c *ProtoClient) sendAndReceive(ctx context.Context, req *pb.Requisicao) (*pb.Resposta, error) {
-	if err := c.setDeadline(ctx); err != nil {
-		return nil, err
-	}
-
-	payload, err := proto.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("proto: falha ao serializar requisição: %w", err)
-	}
-
-	var hdr [4]byte
-	binary.BigEndian.PutUint32(hdr[:], uint32(len(payload)))
-
-	if _, err := c.conn.Write(append(hdr[:], payload...)); err != nil {
-		return nil, fmt.Errorf("proto: falha ao enviar mensagem: %w", err)
-	}
-
-	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
-		return nil, fmt.Errorf("proto: falha ao ler cabeçalho da resposta: %w", err)
-	}
-	size := binary.BigEndian.Uint32(hdr[:])
-
-	respPayload := make([]byte, size)
-	if _, err := io.ReadFull(c.conn, respPayload); err != nil {
-		return nil, fmt.Errorf("proto: falha ao ler payload da resposta: %w", err)
+// protoCodec traduz as operações para pb.Requisicao/pb.Resposta. Os
+// parâmetros e resultados das operações são mapas de strings.
+type protoCodec struct{}
+
+func (protoCodec) Encode(call Call) ([]byte, error) {
+	var opName string
+	var params map[string]string
+	var token string
+
+	switch r := call.Req.(type) {
+	case AuthRequest:
+		return proto.Marshal(&pb.Requisicao{
+			Conteudo: &pb.Requisicao_Auth{
+				Auth: &pb.Auth{
+					AlunoId:   r.AlunoID,
+					Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+				},
+			},
+		})
+	case EchoRequest:
+		token, opName, params = r.Token, "echo", map[string]string{"mensagem": r.Mensagem}
+	case SomaRequest:
+		token, opName, params = r.Token, "soma", map[string]string{"nums": strings.Join(r.Numeros, ",")}
+	case TimestampRequest:
+		token, opName, params = r.Token, "timestamp", map[string]string{}
+	case StatusRequest:
+		token, opName, params = r.Token, "status", map[string]string{"detalhado": strconv.FormatBool(r.Detalhado)}
+	case HistoricoRequest:
+		token, opName, params = r.Token, "historico", map[string]string{}
+		if r.Limite > 0 {
+			params["limite"] = strconv.Itoa(r.Limite)
+		}
+		if r.Offset > 0 {
+			params["offset"] = strconv.Itoa(r.Offset)
+		}
+		if r.Cursor != "" {
+			params["cursor"] = r.Cursor
+		}
+	case InfoRequest:
+		token, opName, params = r.Token, "info", map[string]string{"tipo": r.Tipo}
+	case LogoutRequest:
+		token, opName, params = r.Token, "logout", map[string]string{}
+	default:
+		return nil, newError(ErrInvalidRequest, nil, "requisição inválida para a operação '%s': %T", call.Op, call.Req)
 	}
 
-	var resp pb.Resposta
-	if err := proto.Unmarshal(respPayload, &resp); err != nil {
-		return nil, fmt.Errorf("proto: falha ao desserializar resposta: %w", err)
+	maps.Copy(params, call.TraceContext)
+	if call.AceitaCompressao != "" {
+		params[wire.CampoAceitaCompressao] = call.AceitaCompressao
 	}
-
-	return &resp, nil
-}
-
-func (c *ProtoClient) opRequestHelper(ctx context.Context, token, opName string, params map[string]string) (map[string]string, error) {
-	req := &pb.Requisicao{
+	return proto.Marshal(&pb.Requisicao{
 		Conteudo: &pb.Requisicao_Operacao{
 			Operacao: &pb.Operacao{
 				Token:        token,
@@ -72,75 +81,137 @@ func (c *ProtoClient) opRequestHelper(ctx context.Context, token, opName string,
 				Timestamp:    time.Now().UTC().Format(time.RFC3339Nano),
 			},
 		},
+	})
+}
+
+func (protoCodec) Decode(call Call, msg []byte) (any, error) {
+	resp := &pb.Resposta{}
+	if err := proto.Unmarshal(msg, resp); err != nil {
+		return nil, newError(ErrInvalidResponse, err, "proto: falha ao desserializar resposta")
 	}
 
-	resp, err := c.sendAndReceive(ctx, req)
-	if err != nil {
-		return nil, err
+	if call.Op == OpAuth {
+		return protoAuth(resp)
 	}
 
 	opResp := resp.GetOperacao()
 	if opResp == nil {
-		return nil, fmt.Errorf("proto: resposta de operação inválida (nula)")
-	}
-
-	if errMsg, ok := opResp.Resultado["erro"]; ok && errMsg != "" {
-		return nil, fmt.Errorf("proto: erro do servidor: %s", errMsg)
-	}
-	if errMsg, ok := opResp.Resultado["mensagem"]; ok && errMsg != "" {
-		if opResp.Resultado["erro"] != "" || len(opResp.Resultado) == 1 {
-			return nil, fmt.Errorf("proto: erro do servidor: %s", errMsg)
+		// O servidor não devolve resultado ao logout.
+		if call.Op == OpLogout {
+			return nil, nil
 		}
+		return nil, newError(ErrInvalidResponse, nil, "proto: resposta de operação inválida (nula)")
 	}
-	if errMsg, ok := opResp.Resultado["error"]; ok && errMsg != "" {
-		return nil, fmt.Errorf("proto: erro do servidor: %s", errMsg)
+	r, err := protoResult(call.Op, opResp.Resultado)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(opResp.Resultado) == 0 {
-		return nil, fmt.Errorf("proto: operação falhou - sem dados retornados")
-	}
+	switch call.Op {
+	case OpEcho:
+		t, _ := strconv.Atoi(r["tamanho_mensagem"])
+		return &EchoResponse{
+			MensagemOriginal: r["mensagem_original"],
+			Eco:              r["mensagem_eco"],
+			Timestamp:        r["timestamp_servidor"],
+			Tamanho:          t,
+			HashMD5:          r["hash_md5"],
+		}, nil
 
-	return opResp.Resultado, nil
-}
+	case OpSoma:
+		soma, _ := strconv.ParseFloat(r["soma"], 64)
+		media, _ := strconv.ParseFloat(r["media"], 64)
+		max, _ := strconv.ParseFloat(r["maximo"], 64)
+		min, _ := strconv.ParseFloat(r["minimo"], 64)
+		count, _ := strconv.Atoi(r["quantidade"])
+		return &SomaResponse{
+			Soma:               soma,
+			Media:              media,
+			Maximo:             max,
+			Minimo:             min,
+			NumerosProcessados: count,
+		}, nil
 
-func (c *ProtoClient) Auth(ctx context.Context, alunoID string) (*AuthResponse, error) {
-	req := &pb.Requisicao{
-		Conteudo: &pb.Requisicao_Auth{
-			Auth: &pb.Auth{
-				AlunoId:   alunoID,
-				Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
-			},
-		},
+	case OpTimestamp:
+		resp, err := newTimestampResponse(r["timestamp_iso"], r["timestamp_formatado"], r["timezone"], call.Local)
+		if err != nil {
+			return nil, fmt.Errorf("proto: %w", err)
+		}
+		return resp, nil
+
+	case OpStatus:
+		opCount, _ := strconv.Atoi(r["operacoes_processadas"])
+		return &StatusResponse{
+			Status:               r["status"],
+			OperacoesProcessadas: opCount,
+			Estatisticas:         parseEstatisticasTexto(r["estatisticas_banco"]),
+		}, nil
+
+	case OpHistorico:
+		operacoes, err := parseHistoricoTexto(r["historico"])
+		if err != nil {
+			return nil, err
+		}
+		return &HistoricoResponse{
+			Operacoes:     operacoes,
+			Estatisticas:  parseEstatisticasTexto(r["estatisticas"]),
+			ProximoCursor: r["proximo_cursor"],
+		}, nil
+
+	case OpInfo:
+		var capacidades []string
+		if capStr, ok := r["capacidades"]; ok && capStr != "" {
+			capacidades = strings.Split(capStr, ",")
+		}
+		return &InfoResponse{
+			DescricaoServidor: r["nome"],
+			ProtocoloAtivo:    r["versao"],
+			Capacidades:       capacidades,
+		}, nil
+
+	case OpLogout:
+		return nil, nil
 	}
+	return nil, newError(ErrUnsupported, nil, "proto: operação '%s' não suportada", call.Op)
+}
 
-	resp, err := c.sendAndReceive(ctx, req)
-	if err != nil {
-		return nil, err
+// Fallback assume as informações padrão do servidor protobuf quando o Info
+// falha.
+func (protoCodec) Fallback(call Call, err error) (*Call, any, bool) {
+	if call.Op != OpInfo {
+		return nil, nil, false
 	}
+	return nil, &InfoResponse{
+		DescricaoServidor: "Servidor Protocol Buffers",
+		ProtocoloAtivo:    "protobuf v3",
+		Capacidades:       []string{"auth", "echo", "soma", "timestamp", "status", "historico", "logout"},
+	}, true
+}
 
+func protoAuth(resp *pb.Resposta) (*AuthResponse, error) {
 	opResp := resp.GetOperacao()
 	if opResp == nil {
-		return nil, fmt.Errorf("proto: resposta de autenticação inválida (nula)")
+		return nil, newError(ErrInvalidResponse, nil, "proto: resposta de autenticação inválida (nula)")
 	}
 
 	r := opResp.Resultado
 
 	if r["token"] == "" {
 		for key, value := range r {
-			fmt.Printf("Campo '%s': %s\n", key, value)
+			log.Print(i18n.T("Campo '%s': %s", key, value))
 		}
 
 		if errMsg, ok := r["erro"]; ok {
-			return nil, fmt.Errorf("proto: falha na autenticação: %s", errMsg)
+			return nil, newError(ErrAuth, &ServerError{Operacao: OpAuth, Mensagem: errMsg}, "proto: falha na autenticação")
 		}
 		if errMsg, ok := r["mensagem"]; ok {
-			return nil, fmt.Errorf("proto: falha na autenticação: %s", errMsg)
+			return nil, newError(ErrAuth, &ServerError{Operacao: OpAuth, Mensagem: errMsg}, "proto: falha na autenticação")
 		}
 		if errMsg, ok := r["error"]; ok {
-			return nil, fmt.Errorf("proto: falha na autenticação: %s", errMsg)
+			return nil, newError(ErrAuth, &ServerError{Operacao: OpAuth, Mensagem: errMsg}, "proto: falha na autenticação")
 		}
 
-		return nil, fmt.Errorf("proto: falha na autenticação - sem token retornado")
+		return nil, newError(ErrAuth, nil, "proto: falha na autenticação - sem token retornado")
 	}
 
 	return &AuthResponse{
@@ -150,163 +221,62 @@ func (c *ProtoClient) Auth(ctx context.Context, alunoID string) (*AuthResponse,
 	}, nil
 }
 
-func (c *ProtoClient) OpEcho(ctx context.Context, token, msg string) (*EchoResponse, error) {
-	params := map[string]string{"mensagem": msg}
-	r, err := c.opRequestHelper(ctx, token, "echo", params)
-	if err != nil {
-		return nil, err
+// protoResult confere se o resultado de uma operação indica erro do servidor.
+// parseHistoricoTexto interpreta a lista de operações serializada no campo
+// historico: JSON ou repr de lista Python, como em parseEstatisticasTexto.
+func parseHistoricoTexto(s string) ([]OperacaoInfo, error) {
+	texto := strings.TrimSpace(s)
+	if texto == "" {
+		return nil, nil
 	}
-
-	t, _ := strconv.Atoi(r["tamanho_mensagem"])
-	return &EchoResponse{
-		MensagemOriginal: r["mensagem_original"],
-		Eco:              r["mensagem_eco"],
-		Timestamp:        r["timestamp_servidor"],
-		Tamanho:          t,
-		HashMD5:          r["hash_md5"],
-	}, nil
-}
-
-func (c *ProtoClient) OpSoma(ctx context.Context, token string, numeros []string) (*SomaResponse, error) {
-	numListStr := strings.Join(numeros, ",")
-
-	params := map[string]string{"nums": numListStr}
-
-	r, err := c.opRequestHelper(ctx, token, "soma", params)
-	if err != nil {
-		return nil, err
-	}
-
-	soma, _ := strconv.ParseFloat(r["soma"], 64)
-	media, _ := strconv.ParseFloat(r["media"], 64)
-	max, _ := strconv.ParseFloat(r["maximo"], 64)
-	min, _ := strconv.ParseFloat(r["minimo"], 64)
-	count, _ := strconv.Atoi(r["quantidade"])
-
-	return &SomaResponse{
-		Soma:               soma,
-		Media:              media,
-		Maximo:             max,
-		Minimo:             min,
-		NumerosProcessados: count,
-	}, nil
-}
-
-func (c *ProtoClient) OpTimestamp(ctx context.Context, token string) (*TimestampResponse, error) {
-	params := map[string]string{}
-	r, err := c.opRequestHelper(ctx, token, "timestamp", params)
-	if err != nil {
-		return nil, err
+	var rawOps []any
+	if err := json.Unmarshal([]byte(texto), &rawOps); err != nil {
+		v, errRepr := parsePythonRepr(texto)
+		l, ok := v.([]any)
+		if errRepr != nil || !ok {
+			return nil, newError(ErrInvalidResponse, errRepr, "proto: histórico em formato desconhecido: %s", s)
+		}
+		rawOps = l
 	}
-
-	timestampFormatado := r["timestamp_formatado"]
-	timestampISO := r["timestamp_iso"]
-	tz := "Local"
-
-	if timestampISO != "" {
-		t, err := time.Parse("2006-01-02T15:04:05.999999", timestampISO)
-		if err == nil {
-			localTime := t.UTC().In(time.Local)
-			timestampFormatado = localTime.Format("02/01/2006 15:04:05")
-
-			zoneName, _ := localTime.Zone()
-			tz = zoneName
+	operacoes := make([]OperacaoInfo, 0, len(rawOps))
+	for _, raw := range rawOps {
+		op, ok := raw.(map[string]any)
+		if !ok {
+			return nil, newError(ErrInvalidResponse, nil, "proto: histórico em formato desconhecido: %s", s)
 		}
+		operacoes = append(operacoes, OperacaoInfo{
+			Comando:   textoOuVazio(op["operacao"]),
+			Timestamp: textoOuVazio(op["timestamp"]),
+			Sucesso:   op["sucesso"] == true,
+		})
 	}
-
-	return &TimestampResponse{
-		TimestampFormatado:   timestampFormatado,
-		Timezone:             tz,
-		InformacoesTemporais: timestampISO,
-	}, nil
+	return operacoes, nil
 }
 
-func (c *ProtoClient) OpStatus(ctx context.Context, token string, detalhado bool) (*StatusResponse, error) {
-	params := map[string]string{"detalhado": strconv.FormatBool(detalhado)}
-	r, err := c.opRequestHelper(ctx, token, "status", params)
-	if err != nil {
-		return nil, err
-	}
-
-	opCount, _ := strconv.Atoi(r["operacoes_processadas"])
-
-	statsMap := make(map[string]any)
-	if statsStr, ok := r["estatisticas_banco"]; ok {
-		_ = json.Unmarshal([]byte(statsStr), &statsMap)
+// textoOuVazio formata v, com None/null como texto vazio.
+func textoOuVazio(v any) string {
+	if v == nil {
+		return ""
 	}
-
-	return &StatusResponse{
-		Status:               r["status"],
-		OperacoesProcessadas: opCount,
-		Estatisticas:         statsMap,
-	}, nil
+	return fmt.Sprint(v)
 }
 
-func (c *ProtoClient) OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error) {
-	params := map[string]string{"limite": strconv.Itoa(limite)}
-	r, err := c.opRequestHelper(ctx, token, "historico", params)
-	if err != nil {
-		return nil, err
+func protoResult(opName string, r map[string]string) (map[string]string, error) {
+	if errMsg, ok := r["erro"]; ok && errMsg != "" {
+		return nil, fmt.Errorf("proto: %w", &ServerError{Operacao: opName, Mensagem: errMsg})
 	}
-
-	var operacoes []OperacaoInfo
-	if histStr, ok := r["historico"]; ok && histStr != "" {
-		histStr = strings.ReplaceAll(histStr, "'", "\"")
-		histStr = strings.ReplaceAll(histStr, "True", "true")
-		histStr = strings.ReplaceAll(histStr, "False", "false")
-
-		var rawOps []map[string]any
-		if err := json.Unmarshal([]byte(histStr), &rawOps); err == nil {
-			operacoes = make([]OperacaoInfo, len(rawOps))
-			for i, op := range rawOps {
-				operacoes[i] = OperacaoInfo{
-					Comando:   fmt.Sprintf("%v", op["operacao"]),
-					Timestamp: fmt.Sprintf("%v", op["timestamp"]),
-					Sucesso:   fmt.Sprintf("%v", op["sucesso"]) == "true",
-				}
-			}
+	if errMsg, ok := r["mensagem"]; ok && errMsg != "" {
+		if r["erro"] != "" || len(r) == 1 {
+			return nil, fmt.Errorf("proto: %w", &ServerError{Operacao: opName, Mensagem: errMsg})
 		}
 	}
-
-	statsMap := make(map[string]any)
-	if statsStr, ok := r["estatisticas"]; ok && statsStr != "" {
-		statsStr = strings.ReplaceAll(statsStr, "'", "\"")
-		_ = json.Unmarshal([]byte(statsStr), &statsMap)
-	}
-
-	return &HistoricoResponse{
-		Operacoes:    operacoes,
-		Estatisticas: statsMap,
-	}, nil
-}
-
-func (c *ProtoClient) Info(ctx context.Context, token, tipo string) (*InfoResponse, error) {
-	params := map[string]string{"tipo": tipo}
-	r, err := c.opRequestHelper(ctx, token, "info", params)
-	if err != nil {
-		return &InfoResponse{
-			DescricaoServidor: "Servidor Protocol Buffers",
-			ProtocoloAtivo:    "protobuf v3",
-			Capacidades:       []string{"auth", "echo", "soma", "timestamp", "status", "historico", "logout"},
-		}, nil
+	if errMsg, ok := r["error"]; ok && errMsg != "" {
+		return nil, fmt.Errorf("proto: %w", &ServerError{Operacao: opName, Mensagem: errMsg})
 	}
 
-	var capacidades []string
-	if capStr, ok := r["capacidades"]; ok && capStr != "" {
-		capacidades = strings.Split(capStr, ",")
+	if len(r) == 0 {
+		return nil, newError(ErrInvalidResponse, nil, "proto: operação falhou - sem dados retornados")
 	}
 
-	return &InfoResponse{
-		DescricaoServidor: r["nome"],
-		ProtocoloAtivo:    r["versao"],
-		Capacidades:       capacidades,
-	}, nil
-}
-
-func (c *ProtoClient) Logout(ctx context.Context, token string) error {
-	_, err := c.opRequestHelper(ctx, token, "logout", map[string]string{})
-	if err != nil && err.Error() != "proto: resposta de operação inválida (nula)" {
-		return err
-	}
-	return nil
+	return r, nil
 }
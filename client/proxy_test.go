@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// clienteViaProxy conecta um cliente a um wire.TestServer através de um
+// wire.Proxy com as falhas informadas.
+func clienteViaProxy(t *testing.T, protocolo string, faults wire.Faults) *CodecClient {
+	t.Helper()
+	host, porta := startTestServer(t, protocolo, nil)
+	px, err := wire.NewProxy(protocolo, net.JoinHostPort(host, porta), faults, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	px.Logger = log.New(io.Discard, "", 0)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go px.Serve(ctx, ln)
+
+	c, err := NewProtocolClient(protocolo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, portaProxy, _ := net.SplitHostPort(ln.Addr().String())
+	c.SetPort(portaProxy)
+	if err := c.Connect(context.Background(), "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
+
+func TestProxyFaults(t *testing.T) {
+	quietLog(t)
+	for _, protocolo := range []string{"json", "proto", "msgpack"} {
+		t.Run(protocolo, func(t *testing.T) {
+			t.Run("sem falhas", func(t *testing.T) {
+				c := clienteViaProxy(t, protocolo, wire.Faults{})
+				if _, err := c.Auth(context.Background(), "520402"); err != nil {
+					t.Fatal(err)
+				}
+			})
+
+			t.Run("truncar", func(t *testing.T) {
+				c := clienteViaProxy(t, protocolo, wire.Faults{Truncar: 1})
+				_, err := c.Auth(context.Background(), "520402")
+				if kind := errorKind(err); kind != "conexao" {
+					t.Errorf("Auth: err = %v (%s), quer erro de conexão", err, kind)
+				}
+			})
+
+			t.Run("descartar", func(t *testing.T) {
+				c := clienteViaProxy(t, protocolo, wire.Faults{Descartar: 1})
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				_, err := c.Auth(ctx, "520402")
+				if kind := errorKind(err); kind != "timeout" {
+					t.Errorf("Auth: err = %v (%s), quer timeout", err, kind)
+				}
+			})
+
+			t.Run("atraso", func(t *testing.T) {
+				c := clienteViaProxy(t, protocolo, wire.Faults{Atraso: 50 * time.Millisecond})
+				inicio := time.Now()
+				if _, err := c.Auth(context.Background(), "520402"); err != nil {
+					t.Fatal(err)
+				}
+				if d := time.Since(inicio); d < 50*time.Millisecond {
+					t.Errorf("Auth levou %v, quer pelo menos 50ms", d)
+				}
+			})
+
+			t.Run("reescrever", func(t *testing.T) {
+				c := clienteViaProxy(t, protocolo, wire.Faults{Reescrever: map[string]string{"status": "FORA"}})
+				ctx := context.Background()
+				auth, err := c.Auth(ctx, "520402")
+				if err != nil {
+					t.Fatal(err)
+				}
+				status, err := c.OpStatus(ctx, auth.Token, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if status.Status != "FORA" {
+					t.Errorf("Status = %q, quer FORA", status.Status)
+				}
+			})
+		})
+	}
+}
+
+// TestProxyCorruptLength: o cabeçalho corrompido só existe nos protocolos com
+// prefixo de tamanho, e o cliente precisa recusar a resposta em vez de
+// esperar (ou alocar) os bytes anunciados.
+func TestProxyCorruptLength(t *testing.T) {
+	quietLog(t)
+	for _, protocolo := range []string{"proto", "msgpack", "cbor"} {
+		t.Run(protocolo, func(t *testing.T) {
+			c := clienteViaProxy(t, protocolo, wire.Faults{CorromperTamanho: 1})
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := c.Auth(ctx, "520402")
+			if err == nil {
+				t.Fatal("Auth com o cabeçalho corrompido não falhou")
+			}
+			// Com a semente 1 o tamanho sorteado passa do limite padrão.
+			var sizeErr *FrameTooLargeError
+			if !errors.As(err, &sizeErr) {
+				t.Errorf("Auth: err = %v (%s), quer FrameTooLargeError", err, errorKind(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parsePythonRepr interpreta o repr de um valor literal Python (dict, list,
+// tuple, str, int, float, True, False e None), como o str() de um dicionário
+// enviado por servidores em Python. Dicionários viram map[string]any (chaves
+// que não são texto são formatadas com fmt.Sprint), listas e tuplas []any e
+// números float64, como no encoding/json.
+func parsePythonRepr(s string) (any, error) {
+	p := &pyParser{s: s}
+	v, err := p.valor()
+	if err != nil {
+		return nil, err
+	}
+	if p.espacos(); p.i < len(p.s) {
+		return nil, p.erro("conteúdo após o valor")
+	}
+	return v, nil
+}
+
+type pyParser struct {
+	s string
+	i int
+}
+
+func (p *pyParser) erro(msg string) error {
+	return fmt.Errorf("repr Python, posição %d: %s", p.i, msg)
+}
+
+func (p *pyParser) espacos() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *pyParser) valor() (any, error) {
+	p.espacos()
+	if p.i >= len(p.s) {
+		return nil, p.erro("fim inesperado")
+	}
+	switch c := p.s[p.i]; {
+	case c == '{':
+		return p.dict()
+	case c == '[':
+		return p.sequencia(']')
+	case c == '(':
+		return p.sequencia(')')
+	case c == '\'' || c == '"':
+		return p.texto()
+	case c == '-' || c == '+' || c == '.' || c >= '0' && c <= '9':
+		return p.numero()
+	}
+	for nome, v := range map[string]any{"True": true, "False": false, "None": nil} {
+		if strings.HasPrefix(p.s[p.i:], nome) {
+			p.i += len(nome)
+			return v, nil
+		}
+	}
+	return nil, p.erro("valor inesperado")
+}
+
+func (p *pyParser) dict() (any, error) {
+	p.i++
+	m := make(map[string]any)
+	for {
+		if p.espacos(); p.i < len(p.s) && p.s[p.i] == '}' {
+			p.i++
+			return m, nil
+		}
+		k, err := p.valor()
+		if err != nil {
+			return nil, err
+		}
+		if p.espacos(); p.i >= len(p.s) || p.s[p.i] != ':' {
+			return nil, p.erro("esperado ':'")
+		}
+		p.i++
+		v, err := p.valor()
+		if err != nil {
+			return nil, err
+		}
+		chave, ok := k.(string)
+		if !ok {
+			chave = fmt.Sprint(k)
+		}
+		m[chave] = v
+		if err := p.separador('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *pyParser) sequencia(fim byte) (any, error) {
+	p.i++
+	l := []any{}
+	for {
+		if p.espacos(); p.i < len(p.s) && p.s[p.i] == fim {
+			p.i++
+			return l, nil
+		}
+		v, err := p.valor()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+		if err := p.separador(fim); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separador consome a vírgula entre os itens; o fechamento fica para o laço.
+func (p *pyParser) separador(fim byte) error {
+	p.espacos()
+	switch {
+	case p.i < len(p.s) && p.s[p.i] == ',':
+		p.i++
+		return nil
+	case p.i < len(p.s) && p.s[p.i] == fim:
+		return nil
+	}
+	return p.erro(fmt.Sprintf("esperado ',' ou '%c'", fim))
+}
+
+func (p *pyParser) texto() (any, error) {
+	aspa := p.s[p.i]
+	p.i++
+	var b strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == aspa:
+			p.i++
+			return b.String(), nil
+		case c != '\\':
+			b.WriteByte(c)
+			p.i++
+			continue
+		}
+		p.i++
+		if p.i >= len(p.s) {
+			break
+		}
+		esc := p.s[p.i]
+		p.i++
+		switch esc {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case 'x', 'u', 'U':
+			n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[esc]
+			if p.i+n > len(p.s) {
+				return nil, p.erro("escape incompleto")
+			}
+			r, err := strconv.ParseUint(p.s[p.i:p.i+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return nil, p.erro("escape inválido")
+			}
+			b.WriteRune(rune(r))
+			p.i += n
+		default:
+			// \\, \' e \" ficam com o caractere; escapes desconhecidos
+			// mantêm a barra, como no Python.
+			if esc != '\\' && esc != '\'' && esc != '"' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(esc)
+		}
+	}
+	return nil, errors.New("repr Python: texto sem aspas de fechamento")
+}
+
+func (p *pyParser) numero() (any, error) {
+	inicio := p.i
+	for p.i < len(p.s) && strings.IndexByte("+-.0123456789eE_", p.s[p.i]) >= 0 {
+		p.i++
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(p.s[inicio:p.i], "_", ""), 64)
+	if err != nil {
+		p.i = inicio
+		return nil, p.erro("número inválido")
+	}
+	return f, nil
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenBucket libera até Taxa requisições por segundo, com rajadas de até
+// Rajada requisições. Depois de um erro de limitação do servidor a taxa
+// efetiva cai pela metade e volta a subir aos poucos a cada sucesso.
+type TokenBucket struct {
+	mu       sync.Mutex
+	taxa     float64
+	efetiva  float64
+	rajada   float64
+	tokens   float64
+	atualiza time.Time
+
+	// agora e dormir são trocados nos testes por um relógio falso.
+	agora  func() time.Time
+	dormir func(ctx context.Context, d time.Duration) error
+}
+
+// NewTokenBucket cria um bucket cheio. Uma taxa menor ou igual a zero não
+// limita nada, como em RateLimiter.SetRate.
+func NewTokenBucket(taxa float64, rajada int) *TokenBucket {
+	r := float64(max(rajada, 1))
+	return &TokenBucket{
+		taxa:     taxa,
+		efetiva:  taxa,
+		rajada:   r,
+		tokens:   r,
+		atualiza: time.Now(),
+		agora:    time.Now,
+		dormir:   dormir,
+	}
+}
+
+func dormir(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (b *TokenBucket) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.efetiva
+}
+
+func (b *TokenBucket) refillLocked(agora time.Time) {
+	b.tokens = min(b.rajada, b.tokens+agora.Sub(b.atualiza).Seconds()*b.efetiva)
+	b.atualiza = agora
+}
+
+// Wait bloqueia até haver um token disponível ou ctx ser cancelado.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if b.taxa <= 0 {
+		return ctx.Err()
+	}
+	for {
+		b.mu.Lock()
+		b.refillLocked(b.agora())
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		espera := time.Duration((1 - b.tokens) / b.efetiva * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := b.dormir(ctx, espera); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *TokenBucket) slowDown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.taxa <= 0 {
+		return
+	}
+	b.refillLocked(b.agora())
+	b.efetiva = max(b.efetiva/2, b.taxa/64)
+	b.tokens = min(b.tokens, 0)
+}
+
+func (b *TokenBucket) recover() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.efetiva < b.taxa {
+		b.refillLocked(b.agora())
+		b.efetiva = min(b.taxa, b.efetiva*1.1)
+	}
+}
+
+type semaphore chan struct{}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	<-s
+}
+
+// IsThrottled informa se err é uma recusa do servidor por excesso de
+// requisições.
+func IsThrottled(err error) bool {
+	var srvErr *ServerError
+	if !errors.As(err, &srvErr) {
+		return false
+	}
+	msg := strings.ToLower(srvErr.Mensagem)
+	for _, s := range []string{"rate limit", "too many", "throttl", "muitas requisições", "muitas requisicoes", "limite de requisições", "limite de requisicoes", "sobrecarregado"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimiter limita a vazão (token bucket) e o número de chamadas simultâneas,
+// tanto no total quanto por operação. Um mesmo RateLimiter pode ser
+// compartilhado por vários clientes para impor um orçamento comum.
+type RateLimiter struct {
+	mu          sync.Mutex
+	global      *TokenBucket
+	porOperacao map[string]*TokenBucket
+	emVoo       semaphore
+	emVooPorOp  map[string]semaphore
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		porOperacao: make(map[string]*TokenBucket),
+		emVooPorOp:  make(map[string]semaphore),
+	}
+}
+
+// SetRate define a vazão total em requisições por segundo (0 remove o limite).
+func (l *RateLimiter) SetRate(taxa float64, rajada int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.global = nil
+	if taxa > 0 {
+		l.global = NewTokenBucket(taxa, rajada)
+	}
+}
+
+func (l *RateLimiter) SetOperationRate(op string, taxa float64, rajada int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.porOperacao, op)
+	if taxa > 0 {
+		l.porOperacao[op] = NewTokenBucket(taxa, rajada)
+	}
+}
+
+// SetMaxInFlight limita as chamadas simultâneas (0 remove o limite).
+func (l *RateLimiter) SetMaxInFlight(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.emVoo = nil
+	if n > 0 {
+		l.emVoo = make(semaphore, n)
+	}
+}
+
+func (l *RateLimiter) SetOperationMaxInFlight(op string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.emVooPorOp, op)
+	if n > 0 {
+		l.emVooPorOp[op] = make(semaphore, n)
+	}
+}
+
+func WithRateLimiter(c Client, l *RateLimiter) *InterceptedClient {
+	return WithInterceptors(c, l.Interceptor())
+}
+
+func (l *RateLimiter) Interceptor() Interceptor {
+	return func(ctx context.Context, op string, req any, next Invoker) (any, error) {
+		if op == OpDisconnect {
+			return next(ctx, op, req)
+		}
+
+		l.mu.Lock()
+		var buckets []*TokenBucket
+		if l.global != nil {
+			buckets = append(buckets, l.global)
+		}
+		if b, ok := l.porOperacao[op]; ok {
+			buckets = append(buckets, b)
+		}
+		var sems []semaphore
+		if l.emVoo != nil {
+			sems = append(sems, l.emVoo)
+		}
+		if s, ok := l.emVooPorOp[op]; ok {
+			sems = append(sems, s)
+		}
+		l.mu.Unlock()
+
+		for _, s := range sems {
+			if err := s.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer s.release()
+		}
+		for _, b := range buckets {
+			if err := b.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := next(ctx, op, req)
+		for _, b := range buckets {
+			if IsThrottled(err) {
+				b.slowDown()
+			} else if err == nil {
+				b.recover()
+			}
+		}
+		return res, err
+	}
+}
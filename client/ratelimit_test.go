@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// relogioFalso avança só quando o TokenBucket dorme, e anota cada espera.
+type relogioFalso struct {
+	mu      sync.Mutex
+	t       time.Time
+	esperas []time.Duration
+}
+
+func (r *relogioFalso) agora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.t
+}
+
+func (r *relogioFalso) avanca(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.t = r.t.Add(d)
+}
+
+func (r *relogioFalso) dormir(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.esperas = append(r.esperas, d)
+	r.mu.Unlock()
+	r.avanca(d)
+	return nil
+}
+
+func (r *relogioFalso) zeraEsperas() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.esperas
+	r.esperas = nil
+	return e
+}
+
+func bucketFalso(taxa float64, rajada int) (*TokenBucket, *relogioFalso) {
+	r := &relogioFalso{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewTokenBucket(taxa, rajada)
+	b.atualiza = r.t
+	b.agora = r.agora
+	b.dormir = r.dormir
+	return b, r
+}
+
+func quaseIgual(a, b time.Duration) bool {
+	return math.Abs(float64(a-b)) < float64(time.Microsecond)
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	b, r := bucketFalso(10, 2)
+	ctx := context.Background()
+
+	for range 2 {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e := r.zeraEsperas(); len(e) != 0 {
+		t.Fatalf("rajada inicial esperou %v", e)
+	}
+
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.zeraEsperas(); len(e) != 1 || !quaseIgual(e[0], 100*time.Millisecond) {
+		t.Fatalf("esperas = %v, esperado [100ms]", e)
+	}
+
+	// Um segundo parado repõe só até a rajada, não 10 tokens.
+	r.avanca(time.Second)
+	for range 3 {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e := r.zeraEsperas(); len(e) != 1 || !quaseIgual(e[0], 100*time.Millisecond) {
+		t.Errorf("esperas depois de 1s parado = %v, esperado [100ms]", e)
+	}
+}
+
+func TestTokenBucketContextCancel(t *testing.T) {
+	b, r := bucketFalso(1, 1)
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait com ctx cancelado = %v", err)
+	}
+	if e := r.zeraEsperas(); len(e) != 0 {
+		t.Errorf("esperou %v com ctx cancelado", e)
+	}
+
+	// Com o relógio de verdade a espera também termina com o ctx.
+	b = NewTokenBucket(0.001, 1)
+	b.Wait(context.Background())
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	inicio := time.Now()
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, esperado DeadlineExceeded", err)
+	}
+	if d := time.Since(inicio); d > time.Second {
+		t.Errorf("Wait levou %v para ver o ctx vencido", d)
+	}
+}
+
+func TestTokenBucketAdaptive(t *testing.T) {
+	b, r := bucketFalso(8, 1)
+	ctx := context.Background()
+
+	b.slowDown()
+	if got := b.Rate(); got != 4 {
+		t.Fatalf("Rate depois de slowDown = %v, esperado 4", got)
+	}
+	// slowDown esvazia o bucket: a próxima chamada espera 1/4s.
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.zeraEsperas(); len(e) != 1 || !quaseIgual(e[0], 250*time.Millisecond) {
+		t.Errorf("esperas = %v, esperado [250ms]", e)
+	}
+
+	b.recover()
+	if got := b.Rate(); math.Abs(got-4.4) > 1e-9 {
+		t.Errorf("Rate depois de recover = %v, esperado 4.4", got)
+	}
+
+	for range 20 {
+		b.slowDown()
+	}
+	if got := b.Rate(); got != 8.0/64 {
+		t.Errorf("Rate mínima = %v, esperado %v", got, 8.0/64)
+	}
+	for range 100 {
+		b.recover()
+	}
+	if got := b.Rate(); got != 8 {
+		t.Errorf("Rate recuperada = %v, esperado 8", got)
+	}
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	for _, taxa := range []float64{0, -1} {
+		b, r := bucketFalso(taxa, 1)
+		b.slowDown()
+		for range 100 {
+			if err := b.Wait(context.Background()); err != nil {
+				t.Fatalf("taxa %v: %v", taxa, err)
+			}
+		}
+		if e := r.zeraEsperas(); len(e) != 0 {
+			t.Errorf("taxa %v esperou %v", taxa, e)
+		}
+	}
+}
+
+func TestRateLimiterThrottling(t *testing.T) {
+	l := NewRateLimiter()
+	l.SetRate(1000, 100)
+	in := l.Interceptor()
+
+	throttled := func(ctx context.Context, op string, req any) (any, error) {
+		return nil, &ServerError{Operacao: op, Mensagem: "Too many requests"}
+	}
+	in(context.Background(), OpEcho, EchoRequest{}, throttled)
+	if got := l.global.Rate(); got != 500 {
+		t.Fatalf("Rate depois de recusa = %v, esperado 500", got)
+	}
+
+	ok := func(ctx context.Context, op string, req any) (any, error) { return nil, nil }
+	in(context.Background(), OpEcho, EchoRequest{}, ok)
+	if got := l.global.Rate(); math.Abs(got-550) > 1e-9 {
+		t.Errorf("Rate depois de sucesso = %v, esperado 550", got)
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	l := NewRateLimiter()
+	l.SetMaxInFlight(2)
+	in := l.Interceptor()
+
+	libera := make(chan struct{})
+	var mu sync.Mutex
+	emVoo, pico := 0, 0
+	next := func(ctx context.Context, op string, req any) (any, error) {
+		mu.Lock()
+		emVoo++
+		pico = max(pico, emVoo)
+		mu.Unlock()
+		<-libera
+		mu.Lock()
+		emVoo--
+		mu.Unlock()
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			in(context.Background(), OpEcho, EchoRequest{}, next)
+		}()
+	}
+	for {
+		mu.Lock()
+		n := emVoo
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A terceira chamada fica na fila até o ctx vencer.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := in(ctx, OpEcho, EchoRequest{}, next); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("terceira chamada = %v, esperado DeadlineExceeded", err)
+	}
+
+	close(libera)
+	wg.Wait()
+	if _, err := in(context.Background(), OpEcho, EchoRequest{}, next); err != nil {
+		t.Errorf("chamada depois de liberar = %v", err)
+	}
+	if pico != 2 {
+		t.Errorf("pico de chamadas simultâneas = %d, esperado 2", pico)
+	}
+}
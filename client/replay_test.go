@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+// sessaoGravavel executa uma sessão curta e devolve as respostas recebidas.
+func sessaoGravavel(t *testing.T, c Client, host string) []any {
+	t.Helper()
+	ctx := context.Background()
+	if err := c.Connect(ctx, host); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Disconnect()
+	auth, err := c.Auth(ctx, "520402")
+	if err != nil {
+		t.Fatal(err)
+	}
+	echo, err := c.OpEcho(ctx, auth.Token, "olá replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	soma, err := c.OpSoma(ctx, auth.Token, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := c.OpTimestamp(ctx, auth.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Logout(ctx, auth.Token); err != nil {
+		t.Fatal(err)
+	}
+	return []any{auth, echo, soma, ts.Horario}
+}
+
+func TestReplayCapture(t *testing.T) {
+	quietLog(t)
+	for _, protocolo := range []string{"string", "json", "proto", "msgpack", "cbor"} {
+		t.Run(protocolo, func(t *testing.T) {
+			host, porta := startTestServer(t, protocolo, nil)
+			var captura bytes.Buffer
+			rec := wire.NewRecorder(&captura)
+			c, err := NewProtocolClient(protocolo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.SetPort(porta)
+			c.SetCapture(rec)
+			gravadas := sessaoGravavel(t, c, host)
+			if err := rec.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			frames, err := wire.ReadFrames(&captura)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv, err := wire.NewReplayServer(frames, protocolo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv.Logger = log.New(io.Discard, "", 0)
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go srv.Serve(ctx, ln)
+			_, portaReplay, _ := net.SplitHostPort(ln.Addr().String())
+
+			// A reprodução devolve as mesmas respostas, inclusive o token e o
+			// horário gravados, em todas as conexões.
+			for range 2 {
+				r, _ := NewProtocolClient(protocolo)
+				r.SetPort(portaReplay)
+				if reproduzidas := sessaoGravavel(t, r, "127.0.0.1"); !reflect.DeepEqual(reproduzidas, gravadas) {
+					t.Errorf("reprodução:\n%+v\ngravação:\n%+v", reproduzidas, gravadas)
+				}
+			}
+		})
+	}
+}
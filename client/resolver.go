@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+type Endpoint struct {
+	Host string
+	Port string
+}
+
+// Resolver traduz o nome passado a Connect nos endereços dos servidores do
+// protocolo. Os endereços são tentados na ordem devolvida.
+type Resolver interface {
+	Resolve(ctx context.Context, protocolo, nome string) ([]Endpoint, error)
+}
+
+// SRVResolver consulta registros DNS SRV _sd-<protocolo>._tcp.<nome>, por
+// exemplo _sd-json._tcp.lab.exemplo.com.
+type SRVResolver struct {
+	Resolver *net.Resolver
+}
+
+func (r SRVResolver) Resolve(ctx context.Context, protocolo, nome string) ([]Endpoint, error) {
+	res := r.Resolver
+	if res == nil {
+		res = net.DefaultResolver
+	}
+	_, addrs, err := res.LookupSRV(ctx, "sd-"+protocolo, "tcp", nome)
+	if err != nil {
+		return nil, newError(ErrResolve, err, "falha na consulta SRV de %s", nome)
+	}
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, a := range addrs {
+		endpoints = append(endpoints, Endpoint{
+			Host: strings.TrimSuffix(a.Target, "."),
+			Port: strconv.Itoa(int(a.Port)),
+		})
+	}
+	return endpoints, nil
+}
+
+type RegistryEntry struct {
+	Protocolo string
+	Nome      string
+	Endpoint  Endpoint
+}
+
+// StaticResolver resolve a partir de uma lista fixa de servidores. Entradas
+// sem nome atendem qualquer nome, e o nome vazio aceita todas as entradas do
+// protocolo. Serve tanto para o arquivo de registro (LoadRegistry) quanto como
+// resolvedor falso em testes.
+type StaticResolver struct {
+	Entradas []RegistryEntry
+}
+
+func (r *StaticResolver) Resolve(ctx context.Context, protocolo, nome string) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	for _, e := range r.Entradas {
+		if e.Protocolo == protocolo && (e.Nome == "" || nome == "" || e.Nome == nome) {
+			endpoints = append(endpoints, e.Endpoint)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, newError(ErrNoEndpoints, nil, "nenhum servidor encontrado para '%s' (protocolo %s)", nome, protocolo)
+	}
+	return endpoints, nil
+}
+
+// LoadRegistry lê um arquivo de registro com uma entrada por linha no formato
+// "protocolo host porta [nome]". Linhas vazias e iniciadas por # são ignoradas.
+func LoadRegistry(path string) (*StaticResolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &StaticResolver{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, errors.New(i18n.T("registro %s:%d: esperado 'protocolo host porta [nome]'", path, n))
+		}
+		if _, err := strconv.ParseUint(fields[2], 10, 16); err != nil {
+			return nil, errors.New(i18n.T("registro %s:%d: porta inválida '%s'", path, n, fields[2]))
+		}
+		e := RegistryEntry{Protocolo: fields[0], Endpoint: Endpoint{Host: fields[1], Port: fields[2]}}
+		if len(fields) == 4 {
+			e.Nome = fields[3]
+		}
+		r.Entradas = append(r.Entradas, e)
+	}
+	return r, sc.Err()
+}
+
+func (c *baseClient) SetResolver(r Resolver) {
+	c.resolver = r
+}
+
+func (c *baseClient) endpoints(ctx context.Context, host, port string) ([]Endpoint, error) {
+	if c.resolver == nil {
+		return []Endpoint{{Host: host, Port: port}}, nil
+	}
+	endpoints, err := c.resolver.Resolve(ctx, c.protocol, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) == 0 {
+		return nil, newError(ErrNoEndpoints, nil, "nenhum servidor encontrado para '%s' (protocolo %s)", host, c.protocol)
+	}
+	for i := range endpoints {
+		if endpoints[i].Port == "" {
+			endpoints[i].Port = port
+		}
+	}
+	return endpoints, nil
+}
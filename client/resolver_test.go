@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeResolver devolve endpoints fixos e registra as consultas recebidas.
+type fakeResolver struct {
+	endpoints []Endpoint
+	err       error
+	consultas []string
+}
+
+func (r *fakeResolver) Resolve(ctx context.Context, protocolo, nome string) ([]Endpoint, error) {
+	r.consultas = append(r.consultas, protocolo+" "+nome)
+	return slices.Clone(r.endpoints), r.err
+}
+
+func TestConnectUsesResolver(t *testing.T) {
+	quietLog(t)
+	host, porta := startTestServer(t, "json", nil)
+	// O primeiro endereço não atende; o segundo herda a porta do protocolo.
+	fake := &fakeResolver{endpoints: []Endpoint{{Host: "127.0.0.2", Port: porta}, {Host: host}}}
+	c := NewCodecClient(JSONProtocol)
+	c.SetPort(porta)
+	c.SetResolver(fake)
+	ctx := context.Background()
+	if err := c.Connect(ctx, "lab"); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Disconnect()
+	if !slices.Equal(fake.consultas, []string{"json lab"}) {
+		t.Errorf("consultas = %q", fake.consultas)
+	}
+	if _, err := c.Auth(ctx, "123"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnectResolverErrors(t *testing.T) {
+	falha := errors.New("dns fora do ar")
+	casos := []struct {
+		nome   string
+		fake   *fakeResolver
+		codigo Codigo
+		causa  error
+	}{
+		{"falha na consulta", &fakeResolver{err: falha}, ErrResolve, falha},
+		{"sem endereços", &fakeResolver{}, ErrNoEndpoints, nil},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			c := NewCodecClient(JSONProtocol)
+			c.SetResolver(caso.fake)
+			err := c.Connect(context.Background(), "lab")
+			if !errors.Is(err, caso.codigo) {
+				t.Errorf("erro = %v, esperado %s", err, caso.codigo)
+			}
+			if caso.causa != nil && !errors.Is(err, caso.causa) {
+				t.Errorf("erro = %v, esperado a causa %v", err, caso.causa)
+			}
+		})
+	}
+}
+
+func TestConnectAllEndpointsFail(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, porta, _ := net.SplitHostPort(ln.Addr().String())
+	ln.Close()
+
+	fake := &fakeResolver{endpoints: []Endpoint{{Host: "127.0.0.1", Port: porta}, {Host: "127.0.0.2", Port: porta}}}
+	c := NewCodecClient(JSONProtocol)
+	c.SetResolver(fake)
+	err = c.Connect(context.Background(), "lab")
+	if !errors.Is(err, ErrConnect) {
+		t.Fatalf("erro = %v, esperado %s", err, ErrConnect)
+	}
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) || len(joined.Unwrap()) != 2 {
+		t.Errorf("esperado um erro por endereço: %v", err)
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	r := &StaticResolver{Entradas: []RegistryEntry{
+		{Protocolo: "json", Nome: "lab", Endpoint: Endpoint{Host: "10.0.0.1", Port: "8081"}},
+		{Protocolo: "json", Nome: "local", Endpoint: Endpoint{Host: "127.0.0.1", Port: "8081"}},
+		{Protocolo: "json", Endpoint: Endpoint{Host: "10.0.0.9", Port: "8081"}},
+		{Protocolo: "proto", Nome: "lab", Endpoint: Endpoint{Host: "10.0.0.1", Port: "8082"}},
+	}}
+	hosts := func(protocolo, nome string) []string {
+		eps, err := r.Resolve(context.Background(), protocolo, nome)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var hs []string
+		for _, e := range eps {
+			hs = append(hs, e.Host+":"+e.Port)
+		}
+		return hs
+	}
+	casos := []struct {
+		protocolo, nome string
+		want            []string
+	}{
+		{"json", "lab", []string{"10.0.0.1:8081", "10.0.0.9:8081"}},
+		{"json", "outro", []string{"10.0.0.9:8081"}},
+		{"json", "", []string{"10.0.0.1:8081", "127.0.0.1:8081", "10.0.0.9:8081"}},
+		{"proto", "lab", []string{"10.0.0.1:8082"}},
+	}
+	for _, c := range casos {
+		if got := hosts(c.protocolo, c.nome); !slices.Equal(got, c.want) {
+			t.Errorf("Resolve(%s, %q) = %q, esperado %q", c.protocolo, c.nome, got, c.want)
+		}
+	}
+	if _, err := r.Resolve(context.Background(), "string", "lab"); !errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("protocolo sem entradas: %v, esperado %s", err, ErrNoEndpoints)
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	dir := t.TempDir()
+	escreve := func(conteudo string) string {
+		path := filepath.Join(dir, "servidores.txt")
+		if err := os.WriteFile(path, []byte(conteudo), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	r, err := LoadRegistry(escreve("# comentário\n\njson 10.0.0.1 8081 lab\nproto 10.0.0.2 8082\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RegistryEntry{
+		{Protocolo: "json", Nome: "lab", Endpoint: Endpoint{Host: "10.0.0.1", Port: "8081"}},
+		{Protocolo: "proto", Endpoint: Endpoint{Host: "10.0.0.2", Port: "8082"}},
+	}
+	if !slices.Equal(r.Entradas, want) {
+		t.Errorf("Entradas = %+v, esperado %+v", r.Entradas, want)
+	}
+
+	for _, invalido := range []string{"json 10.0.0.1\n", "json 10.0.0.1 porta\n", "json 10.0.0.1 70000\n", "json h 1 a b\n"} {
+		if _, err := LoadRegistry(escreve(invalido)); err == nil {
+			t.Errorf("LoadRegistry(%q) não falhou", invalido)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Session guarda o token obtido em Auth e o anexa a cada operação. Quando o
+// servidor recusa o token (inválido ou expirado) ou a validade configurada
+// passa, a sessão se autentica de novo e repete a operação uma vez.
+type Session struct {
+	Client  Client
+	AlunoID string
+
+	// Validade é o tempo de vida do token; zero indica que ele só é renovado
+	// quando o servidor o recusa.
+	Validade time.Duration
+
+	mu       sync.Mutex
+	aluno    AuthResponse
+	loginEm  time.Time
+	expiraEm time.Time
+	fechada  bool
+}
+
+func NewSession(c Client, alunoID string) *Session {
+	return &Session{Client: c, AlunoID: alunoID}
+}
+
+// Login autentica alunoID no cliente já conectado e devolve a sessão. O
+// chamador deve encerrá-la com Close, que faz o logout e desconecta.
+func Login(ctx context.Context, c Client, alunoID string) (*Session, error) {
+	s := NewSession(c, alunoID)
+	if _, err := s.Login(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type statusOptions struct {
+	detalhado bool
+}
+
+type StatusOption func(*statusOptions)
+
+func WithDetail() StatusOption {
+	return func(o *statusOptions) { o.detalhado = true }
+}
+
+func (s *Session) Login(ctx context.Context) (*AuthResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fechada {
+		return nil, ErrSessionClosed
+	}
+	if err := s.loginLocked(ctx); err != nil {
+		return nil, err
+	}
+	aluno := s.aluno
+	return &aluno, nil
+}
+
+func (s *Session) loginLocked(ctx context.Context) error {
+	resp, err := s.Client.Auth(ctx, s.AlunoID)
+	if err != nil {
+		return err
+	}
+	s.aluno = *resp
+	s.loginEm = time.Now()
+	s.expiraEm = time.Time{}
+	if s.Validade > 0 {
+		s.expiraEm = s.loginEm.Add(s.Validade)
+	}
+	return nil
+}
+
+func (s *Session) Aluno() AuthResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aluno
+}
+
+func (s *Session) LoginEm() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loginEm
+}
+
+func (s *Session) ExpiraEm() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expiraEm
+}
+
+// token devolve um token válido, autenticando quando ainda não há token ou
+// quando a validade configurada expirou.
+func (s *Session) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fechada {
+		return "", ErrSessionClosed
+	}
+	expirado := !s.expiraEm.IsZero() && time.Now().After(s.expiraEm)
+	if s.aluno.Token == "" || expirado {
+		if err := s.loginLocked(ctx); err != nil {
+			return "", newError(ErrAuth, err, "sessão: falha ao autenticar")
+		}
+	}
+	return s.aluno.Token, nil
+}
+
+func (s *Session) renew(ctx context.Context, recusado string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fechada {
+		return "", ErrSessionClosed
+	}
+	// Outra chamada concorrente pode já ter renovado o token.
+	if s.aluno.Token == recusado {
+		if err := s.loginLocked(ctx); err != nil {
+			return "", newError(ErrAuth, err, "sessão: falha ao reautenticar")
+		}
+	}
+	return s.aluno.Token, nil
+}
+
+func sessionCall[T any](ctx context.Context, s *Session, fn func(token string) (T, error)) (T, error) {
+	var zero T
+	token, err := s.token(ctx)
+	if err != nil {
+		return zero, err
+	}
+	res, err := fn(token)
+	if !IsInvalidToken(err) {
+		return res, err
+	}
+	if token, err = s.renew(ctx, token); err != nil {
+		return zero, err
+	}
+	return fn(token)
+}
+
+func (s *Session) Echo(ctx context.Context, msg string) (*EchoResponse, error) {
+	return sessionCall(ctx, s, func(token string) (*EchoResponse, error) {
+		return s.Client.OpEcho(ctx, token, msg)
+	})
+}
+
+func (s *Session) Soma(ctx context.Context, numeros ...string) (*SomaResponse, error) {
+	return sessionCall(ctx, s, func(token string) (*SomaResponse, error) {
+		return s.Client.OpSoma(ctx, token, numeros)
+	})
+}
+
+func (s *Session) Timestamp(ctx context.Context) (*TimestampResponse, error) {
+	return sessionCall(ctx, s, func(token string) (*TimestampResponse, error) {
+		return s.Client.OpTimestamp(ctx, token)
+	})
+}
+
+func (s *Session) Status(ctx context.Context, opts ...StatusOption) (*StatusResponse, error) {
+	var o statusOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return sessionCall(ctx, s, func(token string) (*StatusResponse, error) {
+		return s.Client.OpStatus(ctx, token, o.detalhado)
+	})
+}
+
+// Historico busca uma única página do histórico; os filtros são aplicados às
+// operações devolvidas. Para percorrer o histórico inteiro use Operacoes.
+func (s *Session) Historico(ctx context.Context, opts ...HistoricoOption) (*HistoricoResponse, error) {
+	o := newHistoricoOptions(opts)
+	resp, err := sessionCall(ctx, s, func(token string) (*HistoricoResponse, error) {
+		if o.offset > 0 {
+			return s.Client.OpHistoricoPagina(ctx, token, Pagina{Limite: o.limite, Offset: o.offset})
+		}
+		return s.Client.OpHistorico(ctx, token, o.limite)
+	})
+	if err != nil || !o.filtra() {
+		return resp, err
+	}
+	filtrada := *resp
+	filtrada.Operacoes = nil
+	for _, op := range resp.Operacoes {
+		if ok, _ := o.match(op); ok {
+			filtrada.Operacoes = append(filtrada.Operacoes, op)
+		}
+	}
+	return &filtrada, nil
+}
+
+func (s *Session) Info(ctx context.Context, tipo string) (*InfoResponse, error) {
+	return sessionCall(ctx, s, func(token string) (*InfoResponse, error) {
+		return s.Client.Info(ctx, token, tipo)
+	})
+}
+
+// Logout encerra a sessão no servidor. Operações posteriores falham com
+// ErrSessionClosed.
+func (s *Session) Logout(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fechada {
+		return nil
+	}
+	s.fechada = true
+	if s.aluno.Token == "" {
+		return nil
+	}
+	token := s.aluno.Token
+	s.aluno.Token = ""
+	return s.Client.Logout(ctx, token)
+}
+
+// Close garante o Logout da sessão, mesmo que o contexto das operações já
+// tenha expirado, e desconecta o cliente.
+func (s *Session) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return errors.Join(s.Logout(ctx), s.Client.Disconnect())
+}
@@ -1,82 +1,219 @@
 package client
 
 import (
-	"bufio"
-	"context"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
 )
 
-type StringClient struct {
-	baseClient
-	reader *bufio.Reader
-	writer *bufio.Writer
-}
+var StringProtocol = Protocol{Nome: "string", Porta: "8080", Codec: stringCodec{}, NewFramer: NewLineFramer}
 
-func NewStringClient() *StringClient {
-	return &StringClient{}
+func NewStringClient() *CodecClient {
+	return NewCodecClient(StringProtocol)
 }
 
-func (c *StringClient) Connect(ctx context.Context, host string) error {
-	if err := c.baseClient.Connect(ctx, host, "8080"); err != nil {
-		return err
-	}
-	c.reader = bufio.NewReader(c.conn)
-	c.writer = bufio.NewWriter(c.conn)
-	return nil
-}
-
-func (c *StringClient) getTimestamp() string {
-	return time.Now().Format(time.RFC3339)
+// stringCodec monta as mensagens "TIPO|chave=valor|...|FIM" do protocolo
+// String. As respostas são lidas por posição.
+type stringCodec struct{}
+
+func (stringCodec) Encode(call Call) ([]byte, error) {
+	var body string
+	switch r := call.Req.(type) {
+	case AuthRequest:
+		body = "AUTH|aluno_id=" + r.AlunoID
+	case EchoRequest:
+		body = fmt.Sprintf("OP|operacao=echo|mensagem=%s|token=%s", r.Mensagem, r.Token)
+	case SomaRequest:
+		body = fmt.Sprintf(`OP|operacao=soma|nums=%s|token=%s`, strings.Join(r.Numeros, ","), r.Token)
+	case TimestampRequest:
+		body = "OP|operacao=timestamp|token=" + r.Token
+	case StatusRequest:
+		body = fmt.Sprintf("OP|operacao=status|detalhado=%t|token=%s", r.Detalhado, r.Token)
+	case HistoricoRequest:
+		body = "OP|operacao=historico"
+		if r.Limite > 0 {
+			body = fmt.Sprintf("%s|limite=%d", body, r.Limite)
+		}
+		if r.Offset > 0 {
+			body = fmt.Sprintf("%s|offset=%d", body, r.Offset)
+		}
+		if r.Cursor != "" {
+			body += "|cursor=" + r.Cursor
+		}
+		body += "|token=" + r.Token
+	case InfoRequest:
+		body = fmt.Sprintf("INFO|tipo=%s|token=%s", r.Tipo, r.Token)
+	case LogoutRequest:
+		body = "LOGOUT|token=" + r.Token
+	default:
+		return nil, newError(ErrInvalidRequest, nil, "requisição inválida para a operação '%s': %T", call.Op, call.Req)
+	}
+	return fmt.Appendf(nil, "%s|timestamp=%s|FIM", body, time.Now().Format(time.RFC3339)), nil
 }
 
-func (c *StringClient) sendAndReceive(ctx context.Context, requestBody string) ([]string, error) {
-	if err := c.setDeadline(ctx); err != nil {
+func (stringCodec) Decode(call Call, msg []byte) (any, error) {
+	parts, err := stringFields(call.Op, string(msg))
+	if err != nil {
 		return nil, err
 	}
 
-	ts := c.getTimestamp()
-	msg := fmt.Sprintf("%s|timestamp=%s|FIM\n", requestBody, ts)
+	switch call.Op {
+	case OpAuth:
+		if len(parts) < 3 {
+			return nil, respostaIncompleta("AUTH", 3, len(parts))
+		}
+		return &AuthResponse{
+			Token:     splitVal(parts[0]),
+			Nome:      splitVal(parts[1]),
+			Matricula: splitVal(parts[2]),
+		}, nil
+
+	case OpEcho:
+		if len(parts) < 5 {
+			return nil, respostaIncompleta("ECHO", 5, len(parts))
+		}
+		tamanho, _ := strconv.Atoi(splitVal(parts[3]))
+		return &EchoResponse{
+			MensagemOriginal: splitVal(parts[0]),
+			Eco:              splitVal(parts[1]),
+			Timestamp:        splitVal(parts[2]),
+			Tamanho:          tamanho,
+			HashMD5:          splitVal(parts[4]),
+		}, nil
+
+	case OpSoma:
+		if len(parts) < 6 {
+			return nil, respostaIncompleta("SOMA", 6, len(parts))
+		}
+		soma, _ := strconv.ParseFloat(splitVal(parts[2]), 64)
+		media, _ := strconv.ParseFloat(splitVal(parts[3]), 64)
+		maximo, _ := strconv.ParseFloat(splitVal(parts[4]), 64)
+		minimo, _ := strconv.ParseFloat(splitVal(parts[5]), 64)
+		count, _ := strconv.Atoi(splitVal(parts[1]))
+		return &SomaResponse{
+			Soma:               soma,
+			Media:              media,
+			Maximo:             maximo,
+			Minimo:             minimo,
+			NumerosProcessados: count,
+		}, nil
+
+	case OpTimestamp:
+		if len(parts) < 3 {
+			return nil, respostaIncompleta("TIMESTAMP", 3, len(parts))
+		}
+		kv := kvMap(parts)
+		iso, ok := kv["timestamp_iso"]
+		if !ok {
+			iso = splitVal(parts[2])
+		}
+		formatado, ok := kv["timestamp_formatado"]
+		if !ok {
+			formatado = splitVal(parts[0])
+		}
+		tz, ok := kv["timezone"]
+		if !ok {
+			tz = splitVal(parts[1])
+		}
+		return newTimestampResponse(iso, formatado, tz, call.Local)
 
-	if _, err := c.writer.WriteString(msg); err != nil {
-		return nil, fmt.Errorf("falha ao escrever: %w", err)
-	}
-	if err := c.writer.Flush(); err != nil {
-		return nil, fmt.Errorf("falha ao dar flush: %w", err)
-	}
+	case OpStatus:
+		if len(parts) < 2 {
+			return nil, respostaIncompleta("STATUS", "2+", len(parts))
+		}
+		resp := &StatusResponse{
+			Status: splitVal(parts[0]),
+		}
+		detalhado := call.Req.(StatusRequest).Detalhado
+		if detalhado && len(parts) > 2 {
+			opCount, _ := strconv.Atoi(splitVal(parts[2]))
+			resp.OperacoesProcessadas = opCount
+			resp.Estatisticas = parseEstatisticasKV(parts[2:])
+		} else if len(parts) > 1 {
+			opCount, _ := strconv.Atoi(splitVal(parts[1]))
+			resp.OperacoesProcessadas = opCount
+		}
+		return resp, nil
 
-	resp, err := c.reader.ReadString('\n')
-	if err != nil {
-		return nil, fmt.Errorf("falha ao ler resposta: %w", err)
+	case OpHistorico:
+		if len(parts) < 2 {
+			return nil, respostaIncompleta("HISTORICO", 2, len(parts))
+		}
+		// Depois da lista de operações vêm as estatísticas, como chave=valor.
+		kv := kvMap(parts[1:])
+		var stats []string
+		for _, p := range parts[1:] {
+			if !strings.HasPrefix(p, "proximo_cursor=") {
+				stats = append(stats, p)
+			}
+		}
+		opStrings := strings.Split(splitVal(parts[0]), ",")
+		operacoes := make([]OperacaoInfo, len(opStrings))
+		for i, opStr := range opStrings {
+			operacoes[i] = OperacaoInfo{Comando: opStr, Timestamp: "N/A", Sucesso: true}
+		}
+		return &HistoricoResponse{
+			Operacoes:     operacoes,
+			Estatisticas:  parseEstatisticasKV(stats),
+			ProximoCursor: kv["proximo_cursor"],
+		}, nil
+
+	case OpInfo:
+		if len(parts) < 3 {
+			return nil, respostaIncompleta("INFO", 3, len(parts))
+		}
+		return &InfoResponse{
+			DescricaoServidor: splitVal(parts[0]),
+			ProtocoloAtivo:    splitVal(parts[1]),
+			Capacidades:       strings.Split(splitVal(parts[2]), ","),
+		}, nil
+
+	case OpLogout:
+		if len(parts) < 1 {
+			return nil, newError(ErrInvalidResponse, nil, "resposta de LOGOUT inválida")
+		}
+		log.Print(i18n.T("[Servidor String]: %s", splitVal(parts[0])))
+		return nil, nil
 	}
+	return nil, newError(ErrUnsupported, nil, "operação '%s' não suportada", call.Op)
+}
 
+// stringFields valida o status da resposta ("OK" ou "ERROR") e devolve os
+// campos seguintes, sem o FIM.
+func stringFields(op, resp string) ([]string, error) {
 	resp = strings.TrimSpace(resp)
 	parts := strings.Split(resp, "|")
 
 	if len(parts) == 0 {
-		return nil, fmt.Errorf("resposta vazia ou inválida do servidor")
+		return nil, newError(ErrInvalidResponse, nil, "resposta vazia ou inválida do servidor")
 	}
 
 	if parts[0] == "ERROR" {
 		if len(parts) > 1 {
-			return nil, fmt.Errorf("erro do servidor: %s", strings.Join(parts[1:], "|"))
+			return nil, &ServerError{Operacao: op, Mensagem: strings.Join(parts[1:], "|")}
 		}
-		return nil, fmt.Errorf("erro desconhecido do servidor")
+		return nil, &ServerError{Operacao: op, Mensagem: i18n.T("erro desconhecido")}
 	}
 
 	if parts[0] != "OK" {
-		return nil, fmt.Errorf("resposta inesperada do servidor: %s", resp)
+		return nil, newError(ErrInvalidResponse, nil, "resposta inesperada do servidor: %s", resp)
 	}
 
-	if len(parts) > 0 && parts[len(parts)-1] == "FIM" {
+	if parts[len(parts)-1] == "FIM" {
 		parts = parts[:len(parts)-1]
 	}
 
 	return parts[1:], nil
 }
 
+func respostaIncompleta(tipo string, esperado any, recebido int) error {
+	return newError(ErrInvalidResponse, nil, "resposta de %s incompleta. Esperado %v campos, recebido %d", tipo, esperado, recebido)
+}
+
 func splitVal(kv string) string {
 	s := strings.SplitN(kv, "=", 2)
 	if len(s) == 2 {
@@ -85,181 +222,12 @@ func splitVal(kv string) string {
 	return kv
 }
 
-func (c *StringClient) Auth(ctx context.Context, alunoID string) (*AuthResponse, error) {
-	reqBody := "AUTH|aluno_id=" + alunoID
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("resposta de AUTH incompleta. Esperado 3 campos, recebido %d", len(parts))
-	}
-
-	return &AuthResponse{
-		Token:     splitVal(parts[0]),
-		Nome:      splitVal(parts[1]),
-		Matricula: splitVal(parts[2]),
-	}, nil
-}
-
-func (c *StringClient) OpEcho(ctx context.Context, token, msg string) (*EchoResponse, error) {
-	reqBody := fmt.Sprintf("OP|operacao=echo|mensagem=%s|token=%s", msg, token)
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 5 {
-		return nil, fmt.Errorf("resposta de ECHO incompleta. Esperado 5 campos, recebido %d", len(parts))
-	}
-
-	tamanho, _ := strconv.Atoi(splitVal(parts[3]))
-	return &EchoResponse{
-		MensagemOriginal: splitVal(parts[0]),
-		Eco:              splitVal(parts[1]),
-		Timestamp:        splitVal(parts[2]),
-		Tamanho:          tamanho,
-		HashMD5:          splitVal(parts[4]),
-	}, nil
-}
-
-func (c *StringClient) OpSoma(ctx context.Context, token string, numeros []string) (*SomaResponse, error) {
-	numListStr := strings.Join(numeros, ",")
-
-	reqBody := fmt.Sprintf(`OP|operacao=soma|nums=%s|token=%s`, numListStr, token)
-
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 6 {
-		return nil, fmt.Errorf("resposta de SOMA incompleta. Esperado 6 campos, recebido %d", len(parts))
-	}
-
-	soma, _ := strconv.ParseFloat(splitVal(parts[2]), 64)
-	media, _ := strconv.ParseFloat(splitVal(parts[3]), 64)
-	maximo, _ := strconv.ParseFloat(splitVal(parts[4]), 64)
-	minimo, _ := strconv.ParseFloat(splitVal(parts[5]), 64)
-	count, _ := strconv.Atoi(splitVal(parts[1]))
-
-	return &SomaResponse{
-		Soma:               soma,
-		Media:              media,
-		Maximo:             maximo,
-		Minimo:             minimo,
-		NumerosProcessados: count,
-	}, nil
-}
-
-func (c *StringClient) OpTimestamp(ctx context.Context, token string) (*TimestampResponse, error) {
-	reqBody := "OP|operacao=timestamp|token=" + token
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("resposta de TIMESTAMP incompleta. Esperado 3 campos, recebido %d", len(parts))
-	}
-
-	return &TimestampResponse{
-		TimestampFormatado:   splitVal(parts[0]),
-		Timezone:             splitVal(parts[1]),
-		InformacoesTemporais: splitVal(parts[2]),
-	}, nil
-}
-
-func (c *StringClient) OpStatus(ctx context.Context, token string, detalhado bool) (*StatusResponse, error) {
-	reqBody := fmt.Sprintf("OP|operacao=status|detalhado=%t|token=%s", detalhado, token)
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("resposta de STATUS incompleta. Esperado 2+ campos, recebido %d", len(parts))
-	}
-
-	resp := &StatusResponse{
-		Status: splitVal(parts[0]),
-	}
-
-	if detalhado && len(parts) > 2 {
-		opCount, _ := strconv.Atoi(splitVal(parts[2]))
-		resp.OperacoesProcessadas = opCount
-		resp.Estatisticas = map[string]any{
-			"raw_stats": strings.Join(parts[2:], "|"),
+func kvMap(parts []string) map[string]string {
+	kv := make(map[string]string, len(parts))
+	for _, p := range parts {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			kv[k] = v
 		}
-	} else if len(parts) > 1 {
-		opCount, _ := strconv.Atoi(splitVal(parts[1]))
-		resp.OperacoesProcessadas = opCount
-	}
-
-	return resp, nil
-}
-
-func (c *StringClient) OpHistorico(ctx context.Context, token string, limite int) (*HistoricoResponse, error) {
-	reqBodyBase := "OP|operacao=historico"
-	if limite > 0 {
-		reqBodyBase = fmt.Sprintf("%s|limite=%d", reqBodyBase, limite)
 	}
-	reqBody := reqBodyBase + "|token=" + token
-
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("resposta de HISTORICO incompleta. Esperado 2 campos, recebido %d", len(parts))
-	}
-
-	opListStr := splitVal(parts[0])
-	opStrings := strings.Split(opListStr, ",")
-	operacoes := make([]OperacaoInfo, len(opStrings))
-	for i, opStr := range opStrings {
-		operacoes[i] = OperacaoInfo{Comando: opStr, Timestamp: "N/A", Sucesso: true}
-	}
-
-	return &HistoricoResponse{
-		Operacoes: operacoes,
-		Estatisticas: map[string]any{
-			"raw_stats": splitVal(parts[1]),
-		},
-	}, nil
-}
-
-func (c *StringClient) Info(ctx context.Context, token, tipo string) (*InfoResponse, error) {
-	reqBody := fmt.Sprintf("INFO|tipo=%s|token=%s", tipo, token)
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("resposta de INFO incompleta. Esperado 3 campos, recebido %d", len(parts))
-	}
-
-	return &InfoResponse{
-		DescricaoServidor: splitVal(parts[0]),
-		ProtocoloAtivo:    splitVal(parts[1]),
-		Capacidades:       strings.Split(splitVal(parts[2]), ","),
-	}, nil
-}
-
-func (c *StringClient) Logout(ctx context.Context, token string) error {
-	reqBody := "LOGOUT|token=" + token
-	parts, err := c.sendAndReceive(ctx, reqBody)
-	if err != nil {
-		return err
-	}
-
-	if len(parts) < 1 {
-		return fmt.Errorf("resposta de LOGOUT inválida")
-	}
-
-	fmt.Printf("[Servidor String]: %s\n", splitVal(parts[0]))
-	return nil
+	return kv
 }
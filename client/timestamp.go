@@ -0,0 +1,78 @@
+package client
+
+import (
+	"strings"
+	"time"
+)
+
+const displayTimeLayout = "02/01/2006 15:04:05"
+
+// Formatos aceitos para os timestamps enviados pelo servidor, na ordem em que
+// são tentados. Formatos sem fuso (isoformat do Python) são interpretados no
+// fuso informado pelo servidor ou, se ele faltar ou for desconhecido, em UTC.
+var serverTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02 15:04:05.999999",
+	displayTimeLayout,
+}
+
+func parseServerTimeIn(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range serverTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, newError(ErrInvalidResponse, nil, "timestamp do servidor em formato desconhecido: %q", s)
+}
+
+// serverLocation devolve o fuso informado pelo servidor; ok é falso quando o
+// nome não é reconhecido e UTC foi usado no lugar dele.
+func serverLocation(tz string) (loc *time.Location, ok bool) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" || tz == "N/A" {
+		return time.UTC, true
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return loc, true
+	}
+	return time.UTC, false
+}
+
+// newTimestampResponse aplica as mesmas regras de interpretação para os três
+// protocolos: usa timestamp_iso, recorre ao timestamp formatado se o primeiro
+// faltar ou for inválido e apresenta o resultado no fuso de exibição do cliente.
+func newTimestampResponse(iso, formatado, tzServidor string, display *time.Location) (*TimestampResponse, error) {
+	origem, conhecido := serverLocation(tzServidor)
+
+	t, err := parseServerTimeIn(iso, origem)
+	if err != nil && strings.TrimSpace(formatado) != "" {
+		t, err = parseServerTimeIn(formatado, origem)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if display == nil {
+		display = time.Local
+	}
+	local := t.In(display)
+	zone, _ := local.Zone()
+	origemZone, _ := t.Zone()
+	if origemZone == "" {
+		origemZone = t.Format("-07:00")
+	}
+	if !conhecido {
+		origemZone = strings.TrimSpace(tzServidor)
+	}
+
+	return &TimestampResponse{
+		TimestampFormatado:   local.Format(displayTimeLayout),
+		Timezone:             zone,
+		InformacoesTemporais: iso,
+		Horario:              t,
+		TimezoneServidor:     origemZone,
+		FusoDesconhecido:     !conhecido,
+	}, nil
+}
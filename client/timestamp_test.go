@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseServerTimeIn(t *testing.T) {
+	fortaleza, err := time.LoadLocation("America/Fortaleza")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		texto string
+		loc   *time.Location
+		want  time.Time
+	}{
+		{"2024-03-05T10:20:30.123456Z", time.UTC, time.Date(2024, 3, 5, 10, 20, 30, 123456000, time.UTC)},
+		// Com fuso no texto, o fuso do servidor é ignorado.
+		{"2024-03-05T10:20:30-03:00", time.UTC, time.Date(2024, 3, 5, 13, 20, 30, 0, time.UTC)},
+		{"2024-03-05T10:20:30.5", fortaleza, time.Date(2024, 3, 5, 10, 20, 30, 500000000, fortaleza)},
+		{" 2024-03-05 10:20:30 ", fortaleza, time.Date(2024, 3, 5, 10, 20, 30, 0, fortaleza)},
+		{"05/03/2024 10:20:30", time.UTC, time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseServerTimeIn(tt.texto, tt.loc)
+		if err != nil {
+			t.Errorf("parseServerTimeIn(%q): %v", tt.texto, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseServerTimeIn(%q) = %v, quer %v", tt.texto, got, tt.want)
+		}
+	}
+
+	for _, texto := range []string{"", "ontem", "2024-13-05T10:20:30", "1709634030"} {
+		if _, err := parseServerTimeIn(texto, time.UTC); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("parseServerTimeIn(%q) = %v, quer ErrInvalidResponse", texto, err)
+		}
+	}
+}
+
+func TestNewTimestampResponse(t *testing.T) {
+	display := time.FixedZone("BRT", -3*3600)
+	tests := []struct {
+		nome             string
+		iso, formatado   string
+		tz               string
+		horario          time.Time
+		tzServidor       string
+		formatadoCliente string
+		fusoDesconhecido bool
+	}{
+		{
+			nome: "iso em UTC", iso: "2024-03-05T10:20:30", tz: "UTC",
+			horario: time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC), tzServidor: "UTC",
+			formatadoCliente: "05/03/2024 07:20:30",
+		},
+		{
+			nome: "fuso do servidor", iso: "2024-03-05T10:20:30", tz: "Asia/Tokyo",
+			horario: time.Date(2024, 3, 5, 1, 20, 30, 0, time.UTC), tzServidor: "JST",
+			formatadoCliente: "04/03/2024 22:20:30",
+		},
+		{
+			nome: "sem fuso", iso: "2024-03-05T10:20:30+01:00", tz: "N/A",
+			horario: time.Date(2024, 3, 5, 9, 20, 30, 0, time.UTC), tzServidor: "+01:00",
+			formatadoCliente: "05/03/2024 06:20:30",
+		},
+		{
+			// Um fuso desconhecido cai em UTC, mas é sinalizado e mantém o nome.
+			nome: "fuso desconhecido", iso: "2024-03-05T10:20:30", tz: "Marte/Olympus",
+			horario: time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC), tzServidor: "Marte/Olympus",
+			formatadoCliente: "05/03/2024 07:20:30", fusoDesconhecido: true,
+		},
+		{
+			nome: "iso inválido usa o formatado", iso: "???", formatado: "05/03/2024 10:20:30", tz: "UTC",
+			horario: time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC), tzServidor: "UTC",
+			formatadoCliente: "05/03/2024 07:20:30",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			r, err := newTimestampResponse(tt.iso, tt.formatado, tt.tz, display)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !r.Horario.Equal(tt.horario) {
+				t.Errorf("Horario = %v, quer %v", r.Horario, tt.horario)
+			}
+			if r.TimezoneServidor != tt.tzServidor {
+				t.Errorf("TimezoneServidor = %q, quer %q", r.TimezoneServidor, tt.tzServidor)
+			}
+			if r.TimestampFormatado != tt.formatadoCliente || r.Timezone != "BRT" {
+				t.Errorf("TimestampFormatado = %q (%s), quer %q (BRT)", r.TimestampFormatado, r.Timezone, tt.formatadoCliente)
+			}
+			if r.FusoDesconhecido != tt.fusoDesconhecido {
+				t.Errorf("FusoDesconhecido = %v, quer %v", r.FusoDesconhecido, tt.fusoDesconhecido)
+			}
+			if r.InformacoesTemporais != tt.iso {
+				t.Errorf("InformacoesTemporais = %q, quer %q", r.InformacoesTemporais, tt.iso)
+			}
+		})
+	}
+
+	if _, err := newTimestampResponse("???", "", "UTC", display); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("timestamp inválido: err = %v, quer ErrInvalidResponse", err)
+	}
+	if _, err := newTimestampResponse("???", "também não", "UTC", display); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("timestamp e formatado inválidos: err = %v, quer ErrInvalidResponse", err)
+	}
+}
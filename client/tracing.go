@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/GuilhermeGalvao1/SD-trab1/client"
+
+// SetTracerProvider substitui o provider global do OpenTelemetry usado pelo
+// cliente. Sem provider configurado os spans não são registrados.
+func (c *baseClient) SetTracerProvider(tp trace.TracerProvider) {
+	c.tracer = tp.Tracer(tracerName)
+}
+
+func (c *baseClient) startSpan(ctx context.Context, method, op string) (context.Context, func(err error)) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = otel.Tracer(tracerName)
+	}
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("sd.protocol", c.protocol),
+			attribute.String("sd.operation", op),
+		))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.SetAttributes(attribute.String("sd.error.type", errorKind(err)))
+		}
+		span.End()
+	}
+}
+
+func recordPayload(ctx context.Context, enviados, recebidos int64) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int64("sd.payload.sent_bytes", enviados),
+		attribute.Int64("sd.payload.received_bytes", recebidos),
+	)
+}
+
+// injectTraceContext devolve os cabeçalhos W3C (traceparent/tracestate) do span
+// atual, segundo o propagador global, para que um servidor cooperativo possa
+// continuar o trace.
+func injectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
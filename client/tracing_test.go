@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestTracer(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	prop := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prop) })
+	return tp, exp
+}
+
+func spanAttr(s tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, nome string) tracetest.SpanStub {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == nome {
+			return s
+		}
+	}
+	t.Fatalf("span %q não registrado; spans: %v", nome, spans)
+	return tracetest.SpanStub{}
+}
+
+func TestTracingSpans(t *testing.T) {
+	quietLog(t)
+	for _, protocolo := range []string{"string", "json", "proto"} {
+		t.Run(protocolo, func(t *testing.T) {
+			tp, exp := newTestTracer(t)
+			c := newTestClient(t, protocolo)
+			c.SetTracerProvider(tp)
+			ctx := context.Background()
+
+			auth, err := c.Auth(ctx, "123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.OpEcho(ctx, auth.Token, "olá"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.OpEcho(ctx, "token-invalido", "olá"); err == nil {
+				t.Fatal("OpEcho com token inválido não falhou")
+			}
+
+			spans := exp.GetSpans()
+			if len(spans) != 3 {
+				t.Fatalf("spans = %d, esperado 3", len(spans))
+			}
+			for i, nome := range []string{"Auth", "OpEcho", "OpEcho"} {
+				if spans[i].Name != nome {
+					t.Errorf("span %d = %q, esperado %q", i, spans[i].Name, nome)
+				}
+			}
+
+			echo := spans[1]
+			if v, _ := spanAttr(echo, "sd.protocol"); v.AsString() != protocolo {
+				t.Errorf("sd.protocol = %q, esperado %q", v.AsString(), protocolo)
+			}
+			if v, _ := spanAttr(echo, "sd.operation"); v.AsString() != OpEcho {
+				t.Errorf("sd.operation = %q, esperado %q", v.AsString(), OpEcho)
+			}
+			for _, key := range []attribute.Key{"sd.payload.sent_bytes", "sd.payload.received_bytes"} {
+				if v, ok := spanAttr(echo, key); !ok || v.AsInt64() <= 0 {
+					t.Errorf("%s = %v, esperado > 0", key, v.Emit())
+				}
+			}
+			if echo.Status.Code != codes.Unset {
+				t.Errorf("status do span com sucesso = %v", echo.Status.Code)
+			}
+
+			falha := spans[2]
+			if falha.Status.Code != codes.Error || falha.Status.Description == "" {
+				t.Errorf("status do span com erro = %+v", falha.Status)
+			}
+			if v, _ := spanAttr(falha, "sd.error.type"); v.AsString() != "servidor" {
+				t.Errorf("sd.error.type = %q, esperado servidor", v.AsString())
+			}
+			if len(falha.Events) == 0 || falha.Events[0].Name != "exception" {
+				t.Errorf("erro não registrado como evento: %v", falha.Events)
+			}
+		})
+	}
+}
+
+func TestTracingConnectSpan(t *testing.T) {
+	tp, exp := newTestTracer(t)
+	c := NewCodecClient(JSONProtocol)
+	c.SetTracerProvider(tp)
+	c.SetPort("1")
+	if err := c.Connect(context.Background(), "127.0.0.1"); err == nil {
+		c.Disconnect()
+		t.Fatal("Connect na porta 1 não falhou")
+	}
+	s := findSpan(t, exp.GetSpans(), "Connect")
+	if s.Status.Code != codes.Error {
+		t.Errorf("status = %v, esperado Error", s.Status.Code)
+	}
+	if v, _ := spanAttr(s, "sd.operation"); v.AsString() != "connect" {
+		t.Errorf("sd.operation = %q", v.AsString())
+	}
+}
+
+// spanCall devolve uma Call com o trace_context de um span ativo e o
+// traceparent esperado.
+func spanCall(t *testing.T, op string, req any) (Call, string) {
+	t.Helper()
+	tp, _ := newTestTracer(t)
+	ctx, span := tp.Tracer("teste").Start(context.Background(), "teste")
+	defer span.End()
+
+	tc := injectTraceContext(ctx)
+	traceparent := tc["traceparent"]
+	sc := span.SpanContext()
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; traceparent != want {
+		t.Fatalf("traceparent = %q, esperado %q", traceparent, want)
+	}
+	return Call{Op: op, Req: req, TraceContext: tc}, traceparent
+}
+
+func TestTraceContextJSONEnvelope(t *testing.T) {
+	quietLog(t)
+	casos := []struct {
+		op  string
+		req any
+	}{
+		{OpAuth, AuthRequest{AlunoID: "123"}},
+		{OpEcho, EchoRequest{Token: "t", Mensagem: "olá"}},
+		{OpLogout, LogoutRequest{Token: "t"}},
+	}
+	for _, caso := range casos {
+		call, traceparent := spanCall(t, caso.op, caso.req)
+		msg, err := JSONProtocol.Codec.Encode(call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var env struct {
+			TraceContext map[string]string `json:"trace_context"`
+		}
+		if err := json.Unmarshal(msg, &env); err != nil {
+			t.Fatal(err)
+		}
+		if env.TraceContext["traceparent"] != traceparent {
+			t.Errorf("%s: trace_context = %v, esperado traceparent %q", caso.op, env.TraceContext, traceparent)
+		}
+	}
+
+	msg, err := JSONProtocol.Codec.Encode(Call{Op: OpEcho, Req: EchoRequest{Token: "t", Mensagem: "olá"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env map[string]any
+	json.Unmarshal(msg, &env)
+	if _, ok := env["trace_context"]; ok {
+		t.Errorf("trace_context enviado sem span: %s", msg)
+	}
+}
+
+func TestTraceContextProtoParametros(t *testing.T) {
+	call, traceparent := spanCall(t, OpEcho, EchoRequest{Token: "t", Mensagem: "olá"})
+	msg, err := ProtoProtocol.Codec.Encode(call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req pb.Requisicao
+	if err := proto.Unmarshal(msg, &req); err != nil {
+		t.Fatal(err)
+	}
+	params := req.GetOperacao().GetParametros()
+	if params["traceparent"] != traceparent {
+		t.Errorf("parametros = %v, esperado traceparent %q", params, traceparent)
+	}
+	if params["mensagem"] != "olá" {
+		t.Errorf("mensagem = %q", params["mensagem"])
+	}
+}
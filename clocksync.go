@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+func runClockSyncCommand(args []string) {
+	fs := flag.NewFlagSet("clocksync", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo a ser usado"))
+	server := addServerFlags(fs, i18n.T("IP do servidor (padrão: o do perfil de configuração ou do -registry)"))
+	amostras := fs.Int("amostras", 10, i18n.T("Número de trocas de timestamp"))
+	intervalo := fs.Duration("intervalo", 200*time.Millisecond, i18n.T("Intervalo entre amostras"))
+	fs.Parse(args)
+
+	if err := server.setup(fs); err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := newClient(*proto)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.configure(c, *proto)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := runClockSync(ctx, c, *server.host, *server.id, *amostras, *intervalo); err != nil {
+		log.Fatalf(i18n.T("\n--- CLOCKSYNC FALHOU ---\n%v\n------------------------"), err)
+	}
+}
+
+func runClockSync(ctx context.Context, c client.Client, host, alunoID string, amostras int, intervalo time.Duration) error {
+	if err := c.Connect(ctx, host); err != nil {
+		return fmt.Errorf(i18n.T("falha ao conectar: %w"), err)
+	}
+	defer c.Disconnect()
+
+	authResp, err := c.Auth(ctx, alunoID)
+	if err != nil {
+		return fmt.Errorf(i18n.T("falha no Auth: %w"), err)
+	}
+	defer c.Logout(ctx, authResp.Token)
+
+	log.Printf(i18n.T("Coletando %d amostras de OpTimestamp..."), amostras)
+	res, err := client.ClockSync(ctx, c, authResp.Token, amostras, intervalo)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range res.Amostras {
+		log.Printf("  #%02d RTT=%-12v Offset=%v", i+1, s.RTT, s.Offset)
+	}
+	log.Printf(i18n.T("Amostras válidas: %d | Descartadas (outliers): %d | RTT mediano: %v"),
+		len(res.Validas), res.Descartadas, res.RTTMediano)
+	log.Printf(i18n.T("Melhor estimativa: Offset=%v ± %v (RTT=%v)"),
+		res.Melhor.Offset, res.Incerteza, res.Melhor.RTT)
+	log.Printf(i18n.T("Offset médio (amostras válidas): %v"), res.OffsetMedio)
+	return nil
+}
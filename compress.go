@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+// sizeRecorder soma os bytes trocados com o servidor numa rodada.
+type sizeRecorder struct {
+	mu        sync.Mutex
+	enviados  int64
+	recebidos int64
+}
+
+func (r *sizeRecorder) ObserveExchange(e client.Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enviados += e.BytesEnviados
+	r.recebidos += e.BytesRecebidos
+}
+
+type compressRound struct {
+	alg       string
+	negociado string
+	enviados  int64
+	recebidos int64
+	duracao   time.Duration
+}
+
+// benchText gera um texto pseudoaleatório (mas reprodutível) de n bytes, com
+// a redundância típica de mensagens reais.
+func benchText(n int) string {
+	palavras := strings.Fields("sistema distribuido cliente servidor mensagem protocolo operacao token resposta requisicao " +
+		"latencia rede pacote dados timestamp historico status soma echo sessao")
+	r := rand.New(rand.NewPCG(1, 2))
+	var b strings.Builder
+	for b.Len() < n {
+		b.WriteString(palavras[r.IntN(len(palavras))])
+		b.WriteByte(' ')
+	}
+	return b.String()[:n]
+}
+
+func runCompressCommand(args []string) {
+	fs := flag.NewFlagSet("compress", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo a ser usado (json, proto, msgpack ou cbor)"))
+	host := fs.String("host", "127.0.0.1", i18n.T("IP do servidor (ex.: o de 'go run . serve')"))
+	id := fs.String("id", "520402", i18n.T("Matrícula do aluno para teste"))
+	tamanho := fs.Int("tamanho", 4096, i18n.T("Tamanho (bytes) da mensagem enviada no OpEcho"))
+	n := fs.Int("n", 10, i18n.T("Número de OpEcho/OpHistorico por algoritmo"))
+	algs := fs.String("algs", "none,gzip,zstd,snappy", i18n.T("Algoritmos comparados"))
+	timeout := fs.Duration("timeout", 60*time.Second, i18n.T("Timeout de cada rodada"))
+	verbose := fs.Bool("v", false, i18n.T("Exibe os logs dos clientes"))
+	lang := addLangFlag(fs)
+	fs.Parse(args)
+
+	if err := setLang(*lang); err != nil {
+		log.Fatal(err)
+	}
+
+	out := quietLogs(*verbose)
+	msg := benchText(*tamanho)
+
+	var rodadas []compressRound
+	for _, alg := range strings.Split(*algs, ",") {
+		alg = strings.TrimSpace(alg)
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		r, err := runCompressRound(ctx, *proto, *host, *id, alg, msg, *n)
+		cancel()
+		if err != nil {
+			out.Fatalf(i18n.T("Rodada '%s' falhou: %v"), alg, err)
+		}
+		rodadas = append(rodadas, r)
+	}
+
+	fmt.Printf(i18n.T("--- COMPRESSÃO %s (OpEcho de %d bytes e OpHistorico, %d vezes) ---\n"), *proto, *tamanho, *n)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, i18n.T("algoritmo\tnegociado\tenviados\trecebidos\ttotal\tvs. primeiro\ttempo\t"))
+	base := rodadas[0].enviados + rodadas[0].recebidos
+	for _, r := range rodadas {
+		total := r.enviados + r.recebidos
+		negociado := r.negociado
+		if negociado == "" {
+			negociado = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.1f%%\t%v\t\n",
+			r.alg, negociado, r.enviados, r.recebidos, total,
+			100*float64(total)/float64(base), r.duracao.Round(time.Microsecond))
+	}
+	tw.Flush()
+}
+
+func runCompressRound(ctx context.Context, proto, host, id, alg, msg string, n int) (compressRound, error) {
+	r := compressRound{alg: alg}
+	c, err := newClient(proto)
+	if err != nil {
+		return r, err
+	}
+	cc, ok := c.(interface {
+		SetCompression(...string) error
+		Compression() string
+	})
+	if !ok {
+		return r, fmt.Errorf(i18n.T("o protocolo '%s' não suporta compressão"), proto)
+	}
+	if err := cc.SetCompression(alg); err != nil {
+		return r, err
+	}
+	var sizes sizeRecorder
+	if mc, ok := c.(interface{ SetMetrics(client.MetricsHook) }); ok {
+		mc.SetMetrics(&sizes)
+	}
+
+	if err := c.Connect(ctx, host); err != nil {
+		return r, err
+	}
+	s, err := client.Login(ctx, c, id)
+	if err != nil {
+		c.Disconnect()
+		return r, err
+	}
+	defer s.Close()
+
+	inicio := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := s.Echo(ctx, msg); err != nil {
+			return r, err
+		}
+		if _, err := s.Historico(ctx, client.Limit(50)); err != nil {
+			return r, err
+		}
+	}
+	r.duracao = time.Since(inicio)
+	r.negociado = cc.Compression()
+	r.enviados, r.recebidos = sizes.enviados, sizes.recebidos
+	return r, nil
+}
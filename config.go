@@ -0,0 +1,358 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile é lido quando nem -config nem SD_CONFIG indicam um
+// arquivo, se existir no diretório atual.
+const defaultConfigFile = "sd.yaml"
+
+// configFile é o arquivo de configuração: perfis nomeados (lab, local,
+// prod...) e o perfil usado quando nem -perfil nem SD_PERFIL escolhem um.
+type configFile struct {
+	Perfil string                  `yaml:"perfil"`
+	Perfis map[string]configPerfil `yaml:"perfis"`
+}
+
+type configPerfil struct {
+	Proto   string         `yaml:"proto"`
+	Host    string         `yaml:"host"`
+	ID      string         `yaml:"id"`
+	Portas  map[string]int `yaml:"portas"`
+	Timeout string         `yaml:"timeout"`
+	TLS     *configTLS     `yaml:"tls"`
+}
+
+// configTLS liga o TLS do perfil; os campos equivalem às flags -tls-*.
+type configTLS struct {
+	CA       string `yaml:"ca"`
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	Servidor string `yaml:"servidor"`
+	Inseguro bool   `yaml:"inseguro"`
+}
+
+// flags devolve os valores do perfil pelo nome da flag correspondente.
+func (p configPerfil) flags() map[string]string {
+	m := make(map[string]string)
+	set := func(nome, v string) {
+		if v != "" {
+			m[nome] = v
+		}
+	}
+	set("proto", p.Proto)
+	set("host", p.Host)
+	set("id", p.ID)
+	set("timeout", p.Timeout)
+	if len(p.Portas) > 0 {
+		var portas []string
+		for _, proto := range slices.Sorted(maps.Keys(p.Portas)) {
+			portas = append(portas, fmt.Sprintf("%s=%d", proto, p.Portas[proto]))
+		}
+		m["portas"] = strings.Join(portas, ",")
+	}
+	if p.TLS != nil {
+		m["tls"] = "true"
+		set("tls-ca", p.TLS.CA)
+		set("tls-cert", p.TLS.Cert)
+		set("tls-key", p.TLS.Key)
+		set("tls-servidor", p.TLS.Servidor)
+		if p.TLS.Inseguro {
+			m["tls-inseguro"] = "true"
+		}
+	}
+	return m
+}
+
+// envFlags são as flags que aceitam variável de ambiente: as que um perfil
+// define, mais a escolha do arquivo e do perfil.
+var envFlags = map[string]bool{
+	"proto": true, "host": true, "id": true, "portas": true, "timeout": true,
+	"tls": true, "tls-ca": true, "tls-cert": true, "tls-key": true, "tls-servidor": true, "tls-inseguro": true,
+	"config": true, "perfil": true,
+}
+
+// envName é a variável de ambiente de uma flag: SD_ seguido do nome em
+// maiúsculas, com _ no lugar de - (SD_HOST, SD_TLS_CA...).
+func envName(flagName string) string {
+	return "SD_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// lookupEnv lê a variável de ambiente de uma flag de envFlags.
+func lookupEnv(nome string) (string, bool) {
+	if !envFlags[nome] {
+		return "", false
+	}
+	return os.LookupEnv(envName(nome))
+}
+
+// lookupFlag devolve o valor de uma flag informada na linha de comando ou,
+// senão, na variável de ambiente.
+func lookupFlag(fs *flag.FlagSet, nome string) (string, bool) {
+	var v string
+	var ok bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == nome {
+			v, ok = f.Value.String(), true
+		}
+	})
+	if !ok {
+		v, ok = lookupEnv(nome)
+	}
+	return v, ok
+}
+
+// applyConfig completa as flags que não vieram da linha de comando com as
+// variáveis de ambiente SD_* de envFlags e, na falta delas, com o perfil
+// escolhido do arquivo de configuração: flag > ambiente > arquivo > padrão.
+func applyConfig(fs *flag.FlagSet) error {
+	path, explicito := lookupFlag(fs, "config")
+	if !explicito {
+		path = defaultConfigFile
+	}
+	var perfil map[string]string
+	cfg, err := loadConfig(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !explicito:
+		// Sem sd.yaml só dá para seguir se nenhum perfil foi pedido.
+		if nome, ok := lookupFlag(fs, "perfil"); ok && nome != "" {
+			return errors.New(i18n.T("perfil '%s' pedido, mas nenhum arquivo de configuração foi encontrado (%s)", nome, path))
+		}
+	case err != nil:
+		return err
+	default:
+		nome, ok := lookupFlag(fs, "perfil")
+		if !ok {
+			nome = cfg.Perfil
+		}
+		if nome != "" {
+			p, ok := cfg.Perfis[nome]
+			if !ok {
+				return errors.New(i18n.T("perfil '%s' não encontrado em %s", nome, path))
+			}
+			perfil = p.flags()
+		}
+	}
+
+	definidas := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { definidas[f.Name] = true })
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if definidas[f.Name] {
+			return
+		}
+		origem := envName(f.Name)
+		v, ok := lookupEnv(f.Name)
+		if !ok {
+			origem = path
+			v, ok = perfil[f.Name]
+		}
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errs = append(errs, errors.New(i18n.T("valor inválido para -%s em %s: %v", f.Name, origem, err)))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func loadConfig(path string) (*configFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cfg configFile
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// parsePortas interpreta -portas ("json=9081,proto=9082").
+func parsePortas(s string) (map[string]string, error) {
+	portas := make(map[string]string)
+	if s == "" {
+		return portas, nil
+	}
+	for _, par := range strings.Split(s, ",") {
+		proto, porta, ok := strings.Cut(strings.TrimSpace(par), "=")
+		if _, conhecido := client.Protocols[proto]; !ok || !conhecido {
+			return nil, errors.New(i18n.T("porta inválida '%s': use protocolo=porta", par))
+		}
+		if n, err := strconv.Atoi(porta); err != nil || n <= 0 || n > 65535 {
+			return nil, errors.New(i18n.T("porta inválida '%s': use protocolo=porta", par))
+		}
+		portas[proto] = porta
+	}
+	return portas, nil
+}
+
+type tlsFlags struct {
+	habilitado *bool
+	ca         *string
+	cert       *string
+	key        *string
+	servidor   *string
+	inseguro   *bool
+}
+
+func addTLSFlags(fs *flag.FlagSet) tlsFlags {
+	return tlsFlags{
+		habilitado: fs.Bool("tls", false, i18n.T("Conecta aos servidores com TLS")),
+		ca:         fs.String("tls-ca", "", i18n.T("Certificados de CA (PEM) aceitos, além dos do sistema")),
+		cert:       fs.String("tls-cert", "", i18n.T("Certificado do cliente (PEM), exige -tls-key")),
+		key:        fs.String("tls-key", "", i18n.T("Chave privada (PEM) de -tls-cert")),
+		servidor:   fs.String("tls-servidor", "", i18n.T("Nome esperado no certificado do servidor (padrão: o host)")),
+		inseguro:   fs.Bool("tls-inseguro", false, i18n.T("Não verifica o certificado do servidor")),
+	}
+}
+
+// config devolve a configuração TLS das flags, ou nil sem -tls.
+func (t tlsFlags) config() (*tls.Config, error) {
+	if !*t.habilitado {
+		return nil, nil
+	}
+	cfg := &tls.Config{ServerName: *t.servidor, InsecureSkipVerify: *t.inseguro}
+	if *t.ca != "" {
+		pem, err := os.ReadFile(*t.ca)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(i18n.T("nenhum certificado PEM em %s", *t.ca))
+		}
+		cfg.RootCAs = pool
+	}
+	if *t.cert != "" || *t.key != "" {
+		cert, err := tls.LoadX509KeyPair(*t.cert, *t.key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// serverFlags são as opções de conexão comuns ao teste e aos subcomandos que
+// falam com o servidor, incluindo -config e -perfil.
+type serverFlags struct {
+	host     *string
+	id       *string
+	portas   *string
+	registry *string
+	srv      *bool
+	maxFrame *int64
+	lang     *string
+	tls      tlsFlags
+
+	portasProto map[string]string
+	tlsConfig   *tls.Config
+	resolver    client.Resolver
+}
+
+func addServerFlags(fs *flag.FlagSet, hostUso string) *serverFlags {
+	f := &serverFlags{
+		host:     fs.String("host", "", hostUso),
+		id:       fs.String("id", "520402", i18n.T("Matrícula do aluno para teste")),
+		portas:   fs.String("portas", "", i18n.T("Portas por protocolo no lugar das padrão (ex.: json=9081,proto=9082)")),
+		registry: fs.String("registry", "", i18n.T("Arquivo de registro de servidores (linhas 'protocolo host porta [nome]')")),
+		srv:      fs.Bool("srv", false, i18n.T("Resolve -host como domínio DNS com registros SRV _sd-<protocolo>._tcp")),
+		maxFrame: fs.Int64("max-frame", client.DefaultMaxFrameSize, i18n.T("Tamanho máximo (bytes) de uma resposta do servidor")),
+		lang:     addLangFlag(fs),
+		tls:      addTLSFlags(fs),
+	}
+	fs.String("config", "", i18n.T("Arquivo de configuração YAML com perfis (padrão: sd.yaml, se existir)"))
+	fs.String("perfil", "", i18n.T("Perfil do arquivo de configuração (lab, local, prod...)"))
+	return f
+}
+
+// setup completa as flags com o arquivo de configuração e o ambiente, ajusta o
+// idioma e prepara as portas, o TLS e o resolvedor usados por configure.
+func (f *serverFlags) setup(fs *flag.FlagSet) error {
+	// -lang não vem do ambiente nem do perfil, então já vale para os erros
+	// do arquivo de configuração.
+	if err := setLang(*f.lang); err != nil {
+		return err
+	}
+	// -config e -perfil só são lidos por applyConfig.
+	if err := applyConfig(fs); err != nil {
+		return err
+	}
+	// Sem -host, o servidor vem do registro: um nome vazio aceita todas as
+	// entradas do protocolo.
+	if *f.host == "" && *f.registry == "" {
+		return errors.New(i18n.T("Erro: informe o servidor com -host, SD_HOST, um perfil do arquivo de configuração ou -registry"))
+	}
+
+	var err error
+	if f.portasProto, err = parsePortas(*f.portas); err != nil {
+		return err
+	}
+	if f.tlsConfig, err = f.tls.config(); err != nil {
+		return errors.New(i18n.T("falha na configuração TLS: %v", err))
+	}
+	switch {
+	case *f.registry != "" && *f.srv:
+		return errors.New(i18n.T("Erro: use apenas um de -registry e -srv"))
+	case *f.registry != "":
+		r, err := client.LoadRegistry(*f.registry)
+		if err != nil {
+			return errors.New(i18n.T("falha ao ler registro de servidores: %v", err))
+		}
+		f.resolver = r
+	case *f.srv:
+		f.resolver = client.SRVResolver{}
+	}
+	return nil
+}
+
+// configure aplica ao cliente do protocolo as opções preparadas por setup.
+func (f *serverFlags) configure(c client.Client, proto string) {
+	if mc, ok := c.(interface{ SetMaxFrameSize(int64) }); ok {
+		mc.SetMaxFrameSize(*f.maxFrame)
+	}
+	if rc, ok := c.(interface{ SetResolver(client.Resolver) }); ok && f.resolver != nil {
+		rc.SetResolver(f.resolver)
+	}
+	if pc, ok := c.(interface{ SetPort(string) }); ok && f.portasProto[proto] != "" {
+		pc.SetPort(f.portasProto[proto])
+	}
+	if tc, ok := c.(interface{ SetTLS(*tls.Config) }); ok && f.tlsConfig != nil {
+		tc.SetTLS(f.tlsConfig)
+	}
+}
+
+func addLangFlag(fs *flag.FlagSet) *string {
+	return fs.String("lang", "", i18n.T("Idioma das mensagens: pt ou en (padrão: LANG)"))
+}
+
+// setLang troca o idioma pelo de -lang; vazio mantém o de LANG.
+func setLang(lang string) error {
+	if lang == "" {
+		return nil
+	}
+	return i18n.Set(lang)
+}
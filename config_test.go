@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const configTeste = `perfil: lab
+perfis:
+  lab:
+    host: 10.0.0.1
+    id: "111"
+    proto: proto
+    timeout: 30s
+    portas:
+      json: 9081
+  prod:
+    host: sd.exemplo.com
+    tls:
+      ca: ca.pem
+      inseguro: true
+  quebrado:
+    timeout: nunca
+`
+
+// flagsTeste monta as flags como o comando principal: -proto e -timeout, mais
+// as comuns de addServerFlags.
+func flagsTeste() *flag.FlagSet {
+	fs := flag.NewFlagSet("teste", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.String("proto", "json", "")
+	fs.Duration("timeout", 60*time.Second, "")
+	addServerFlags(fs, "")
+	return fs
+}
+
+// semAmbienteSD isola o teste das variáveis SD_* de quem roda os testes.
+func semAmbienteSD(t *testing.T) {
+	for _, kv := range os.Environ() {
+		if nome, _, _ := strings.Cut(kv, "="); strings.HasPrefix(nome, "SD_") {
+			t.Setenv(nome, "")
+			os.Unsetenv(nome)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	casos := []struct {
+		nome    string
+		arquivo bool
+		env     map[string]string
+		args    []string
+		want    map[string]string
+		erro    string
+	}{
+		{
+			nome: "padrão sem arquivo",
+			want: map[string]string{"host": "", "id": "520402", "proto": "json", "timeout": "1m0s", "tls": "false"},
+		},
+		{
+			nome:    "perfil padrão do arquivo",
+			arquivo: true,
+			want:    map[string]string{"host": "10.0.0.1", "id": "111", "proto": "proto", "timeout": "30s", "portas": "json=9081"},
+		},
+		{
+			nome:    "ambiente sobre o arquivo",
+			arquivo: true,
+			env:     map[string]string{"SD_HOST": "10.0.0.2", "SD_TIMEOUT": "5s"},
+			want:    map[string]string{"host": "10.0.0.2", "id": "111", "timeout": "5s"},
+		},
+		{
+			nome:    "flag sobre o ambiente",
+			arquivo: true,
+			env:     map[string]string{"SD_HOST": "10.0.0.2"},
+			args:    []string{"-host=10.0.0.3", "-id=222"},
+			want:    map[string]string{"host": "10.0.0.3", "id": "222", "proto": "proto"},
+		},
+		{
+			nome:    "perfil pelo ambiente",
+			arquivo: true,
+			env:     map[string]string{"SD_PERFIL": "prod"},
+			want:    map[string]string{"host": "sd.exemplo.com", "id": "520402", "tls": "true", "tls-ca": "ca.pem", "tls-inseguro": "true", "timeout": "1m0s"},
+		},
+		{
+			nome:    "perfil pela flag sobre o ambiente",
+			arquivo: true,
+			env:     map[string]string{"SD_PERFIL": "prod"},
+			args:    []string{"-perfil=lab"},
+			want:    map[string]string{"host": "10.0.0.1", "tls": "false"},
+		},
+		{
+			nome:    "só as flags documentadas vêm do ambiente",
+			arquivo: true,
+			env:     map[string]string{"SD_MAX_FRAME": "1", "SD_REGISTRY": "servidores.txt", "SD_LANG": "en", "SD_SRV": "true"},
+			want:    map[string]string{"max-frame": "16777216", "registry": "", "lang": "", "srv": "false", "host": "10.0.0.1"},
+		},
+		{
+			nome:    "perfil desconhecido",
+			arquivo: true,
+			args:    []string{"-perfil=nada"},
+			erro:    "perfil 'nada' não encontrado",
+		},
+		{
+			nome: "perfil sem arquivo",
+			env:  map[string]string{"SD_PERFIL": "lab"},
+			erro: "perfil 'lab' pedido, mas nenhum arquivo de configuração foi encontrado",
+		},
+		{
+			nome: "-config inexistente",
+			args: []string{"-config=outro.yaml"},
+			erro: "outro.yaml",
+		},
+		{
+			nome:    "valor inválido no ambiente",
+			arquivo: true,
+			env:     map[string]string{"SD_TIMEOUT": "logo"},
+			erro:    "valor inválido para -timeout em SD_TIMEOUT",
+		},
+		{
+			nome:    "valor inválido no arquivo",
+			arquivo: true,
+			args:    []string{"-perfil=quebrado"},
+			erro:    "valor inválido para -timeout em sd.yaml",
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			semAmbienteSD(t)
+			for k, v := range caso.env {
+				t.Setenv(k, v)
+			}
+			dir := t.TempDir()
+			t.Chdir(dir)
+			if caso.arquivo {
+				if err := os.WriteFile(filepath.Join(dir, defaultConfigFile), []byte(configTeste), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fs := flagsTeste()
+			if err := fs.Parse(caso.args); err != nil {
+				t.Fatal(err)
+			}
+			err := applyConfig(fs)
+			if caso.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), caso.erro) {
+					t.Fatalf("applyConfig = %v, esperado erro com %q", err, caso.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for nome, want := range caso.want {
+				if got := fs.Lookup(nome).Value.String(); got != want {
+					t.Errorf("-%s = %q, esperado %q", nome, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupFlag(t *testing.T) {
+	semAmbienteSD(t)
+	t.Setenv("SD_PERFIL", "prod")
+	t.Setenv("SD_LANG", "en")
+	fs := flagsTeste()
+	if err := fs.Parse([]string{"-config=x.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	casos := []struct {
+		nome string
+		v    string
+		ok   bool
+	}{
+		{"config", "x.yaml", true},
+		{"perfil", "prod", true},
+		{"lang", "", false},
+		{"host", "", false},
+	}
+	for _, caso := range casos {
+		if v, ok := lookupFlag(fs, caso.nome); v != caso.v || ok != caso.ok {
+			t.Errorf("lookupFlag(%s) = %q, %v; esperado %q, %v", caso.nome, v, ok, caso.v, caso.ok)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+func runDecodeCommand(args []string) {
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo do fluxo capturado (string, json, proto, msgpack ou cbor)"))
+	file := fs.String("file", "-", i18n.T("Arquivo com o fluxo TCP ('-' para a entrada padrão)"))
+	formato := fs.String("formato", "auto", i18n.T("Formato do arquivo: auto, bin, hex ou captura"))
+	direcao := fs.String("direcao", "auto", i18n.T("Direção do fluxo protobuf: auto, req ou resp"))
+	lang := addLangFlag(fs)
+	fs.Parse(args)
+
+	if err := setLang(*lang); err != nil {
+		log.Fatal(err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao abrir %s: %v"), *file, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		log.Fatalf(i18n.T("falha ao ler entrada: %v"), err)
+	}
+
+	dir := *direcao
+	if dir == "auto" {
+		dir = ""
+	}
+	if err := decodeStream(os.Stdout, data, *proto, *formato, dir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func decodeStream(w io.Writer, data []byte, proto, formato, direcao string) error {
+	if formato == "auto" {
+		formato = detectFormat(data)
+	}
+
+	if formato == "captura" {
+		frames, err := wire.ReadFrames(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		for i, f := range frames {
+			fmt.Fprintf(w, i18n.T("--- #%d %s sessão %d %s (%s) ---\n"),
+				i+1, f.Timestamp.Format("15:04:05.000000"), f.Sessao, f.Direcao, f.Protocolo)
+			if err := wire.DecodeFrame(w, f.Protocolo, f.Direcao, f.Dados); err != nil {
+				fmt.Fprintf(w, i18n.T("  erro: %v\n"), err)
+			}
+		}
+		return nil
+	}
+
+	if formato == "hex" {
+		var err error
+		if data, err = wire.ParseHexDump(bytes.NewReader(data)); err != nil {
+			return err
+		}
+	} else if formato != "bin" {
+		return fmt.Errorf(i18n.T("formato '%s' desconhecido. Use auto, bin, hex ou captura"), formato)
+	}
+
+	frames, err := wire.SplitStream(proto, data)
+	if err != nil {
+		return err
+	}
+	for i, f := range frames {
+		fmt.Fprintf(w, "--- #%d (%d bytes) ---\n", i+1, len(f))
+		if err := wire.DecodeFrame(w, proto, direcao, f); err != nil {
+			fmt.Fprintf(w, i18n.T("  erro: %v\n"), err)
+		}
+	}
+	return nil
+}
+
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte(`{"timestamp"`)) {
+		return "captura"
+	}
+	if _, err := wire.ParseHexDump(bytes.NewReader(trimmed)); err == nil && len(trimmed) > 0 {
+		return "hex"
+	}
+	return "bin"
+}
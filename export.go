@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/parquet-go/parquet-go"
+)
+
+// historicoRow é uma operação do histórico exportada; Timestamp é nulo quando
+// o servidor não informa um horário reconhecível (protocolo String).
+type historicoRow struct {
+	Comando   string     `json:"comando" parquet:"comando"`
+	Timestamp *time.Time `json:"timestamp" parquet:"timestamp,optional"`
+	Sucesso   bool       `json:"sucesso" parquet:"sucesso"`
+}
+
+func (historicoRow) csvHeader() []string {
+	return []string{"comando", "timestamp", "sucesso"}
+}
+
+func (r historicoRow) csvRecord() []string {
+	ts := ""
+	if r.Timestamp != nil {
+		ts = r.Timestamp.Format(time.RFC3339Nano)
+	}
+	return []string{r.Comando, ts, strconv.FormatBool(r.Sucesso)}
+}
+
+// estatisticaRow é um campo de OpStatus, das estatísticas de OpHistorico ou do
+// resumo das operações exportadas.
+// Objetos aninhados viram chaves com ponto (por_operacao.echo),
+// e Numero guarda o valor quando ele é numérico.
+type estatisticaRow struct {
+	Origem string   `json:"origem" parquet:"origem"`
+	Chave  string   `json:"chave" parquet:"chave"`
+	Valor  string   `json:"valor" parquet:"valor"`
+	Numero *float64 `json:"numero" parquet:"numero,optional"`
+}
+
+func (estatisticaRow) csvHeader() []string {
+	return []string{"origem", "chave", "valor", "numero"}
+}
+
+func (r estatisticaRow) csvRecord() []string {
+	num := ""
+	if r.Numero != nil {
+		num = strconv.FormatFloat(*r.Numero, 'f', -1, 64)
+	}
+	return []string{r.Origem, r.Chave, r.Valor, num}
+}
+
+type exportRow interface {
+	csvHeader() []string
+	csvRecord() []string
+}
+
+var exportFormats = map[string]string{
+	"csv":     ".csv",
+	"ndjson":  ".ndjson",
+	"parquet": ".parquet",
+}
+
+func runExportCommand(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	proto := fs.String("proto", "json", i18n.T("Protocolo a ser usado (string, json, proto, msgpack ou cbor)"))
+	server := addServerFlags(fs, i18n.T("IP do servidor (padrão: o do perfil de configuração ou do -registry)"))
+	formato := fs.String("formato", "csv", i18n.T("Formato dos arquivos: csv, ndjson ou parquet"))
+	saida := fs.String("saida", "export", i18n.T("Prefixo dos arquivos gerados (<saida>-historico e <saida>-estatisticas)"))
+	pagina := fs.Int("pagina", client.DefaultPageSize, i18n.T("Operações do histórico pedidas por página"))
+	limite := fs.Int("limite", 0, i18n.T("Máximo de operações exportadas (0 para todas)"))
+	ops := fs.String("ops", "", i18n.T("Exporta só estas operações (lista separada por vírgulas)"))
+	timeout := fs.Duration("timeout", 60*time.Second, i18n.T("Timeout da exportação"))
+	verbose := fs.Bool("v", false, i18n.T("Exibe os logs do cliente"))
+	fs.Parse(args)
+
+	if err := server.setup(fs); err != nil {
+		log.Fatal(err)
+	}
+	ext, ok := exportFormats[*formato]
+	if !ok {
+		log.Fatalf(i18n.T("Formato '%s' desconhecido. Use 'csv', 'ndjson' ou 'parquet'."), *formato)
+	}
+	out := quietLogs(*verbose)
+
+	c, err := newClient(*proto)
+	if err != nil {
+		out.Fatal(err)
+	}
+	server.configure(c, *proto)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	opts := []client.HistoricoOption{client.PageSize(*pagina), client.Limit(*limite)}
+	if *ops != "" {
+		opts = append(opts, client.WithOperation(strings.Split(*ops, ",")...))
+	}
+	if err := runExport(ctx, c, *server.host, *server.id, *formato, *saida+"-historico"+ext, *saida+"-estatisticas"+ext, opts); err != nil {
+		out.Fatal(err)
+	}
+}
+
+// runExport devolve os erros em vez de encerrar o programa, para que o Logout
+// e o Disconnect adiados rodem sempre.
+func runExport(ctx context.Context, c client.Client, host, alunoID, formato, histPath, statsPath string, opts []client.HistoricoOption) error {
+	if err := c.Connect(ctx, host); err != nil {
+		return fmt.Errorf(i18n.T("falha ao conectar: %w"), err)
+	}
+	s, err := client.Login(ctx, c, alunoID)
+	if err != nil {
+		c.Disconnect()
+		return fmt.Errorf(i18n.T("falha ao autenticar: %w"), err)
+	}
+	defer s.Close()
+
+	var historico []historicoRow
+	for op, err := range s.Operacoes(ctx, opts...) {
+		if err != nil {
+			return fmt.Errorf(i18n.T("falha ao ler o histórico: %w"), err)
+		}
+		row := historicoRow{Comando: op.Comando, Sucesso: op.Sucesso}
+		if t, err := op.Horario(); err == nil {
+			row.Timestamp = &t
+		}
+		historico = append(historico, row)
+	}
+	// O histórico é lido da operação mais recente para a mais antiga.
+	slices.Reverse(historico)
+
+	// Alguns servidores (como o de `serve`) contam as estatísticas só sobre a
+	// página devolvida: sem limite elas cobrem o histórico inteiro.
+	hist, err := s.Historico(ctx)
+	if err != nil {
+		return fmt.Errorf(i18n.T("falha no OpHistorico: %w"), err)
+	}
+	status, err := s.Status(ctx, client.WithDetail())
+	if err != nil {
+		return fmt.Errorf(i18n.T("falha no OpStatus: %w"), err)
+	}
+	estatisticas := []estatisticaRow{
+		newEstatisticaRow("status", "status", status.Status),
+		newEstatisticaRow("status", "operacoes_processadas", status.OperacoesProcessadas),
+	}
+	estatisticas = appendEstatisticas(estatisticas, "status", "", status.Estatisticas.Map())
+	estatisticas = appendEstatisticas(estatisticas, "historico", "", hist.Estatisticas.Map())
+	estatisticas = appendEstatisticas(estatisticas, "historico_exportado", "", estatisticasHistorico(historico))
+
+	if err := writeExport(histPath, formato, historico); err != nil {
+		return fmt.Errorf(i18n.T("falha ao gravar %s: %w"), histPath, err)
+	}
+	if err := writeExport(statsPath, formato, estatisticas); err != nil {
+		return fmt.Errorf(i18n.T("falha ao gravar %s: %w"), statsPath, err)
+	}
+	fmt.Printf(i18n.T("%d operações em %s\n%d estatísticas em %s\n"), len(historico), histPath, len(estatisticas), statsPath)
+	return nil
+}
+
+// estatisticasHistorico resume as operações exportadas (origem
+// historico_exportado), com os mesmos nomes de Estatisticas.Map; com -ops ou
+// -limite o resumo cobre só o que foi exportado.
+func estatisticasHistorico(rows []historicoRow) map[string]any {
+	var sucessos int
+	porOp := make(map[string]any)
+	for _, r := range rows {
+		if r.Sucesso {
+			sucessos++
+		}
+		n, _ := porOp[r.Comando].(int)
+		porOp[r.Comando] = n + 1
+	}
+	m := map[string]any{
+		"total_operacoes": len(rows),
+		"sucessos":        sucessos,
+		"falhas":          len(rows) - sucessos,
+	}
+	if len(porOp) > 0 {
+		m["por_operacao"] = porOp
+	}
+	return m
+}
+
+func newEstatisticaRow(origem, chave string, v any) estatisticaRow {
+	row := estatisticaRow{Origem: origem, Chave: chave, Valor: fmt.Sprint(v)}
+	switch n := v.(type) {
+	case float64:
+		row.Numero = &n
+	case int:
+		f := float64(n)
+		row.Numero = &f
+	case string:
+		if f, err := strconv.ParseFloat(n, 64); err == nil {
+			row.Numero = &f
+		}
+	}
+	return row
+}
+
+func appendEstatisticas(rows []estatisticaRow, origem, prefixo string, m map[string]any) []estatisticaRow {
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		chave := prefixo + k
+		if aninhado, ok := m[k].(map[string]any); ok {
+			rows = appendEstatisticas(rows, origem, chave+".", aninhado)
+			continue
+		}
+		rows = append(rows, newEstatisticaRow(origem, chave, m[k]))
+	}
+	return rows
+}
+
+func writeExport[T exportRow](path, formato string, rows []T) error {
+	if formato == "parquet" {
+		return parquet.WriteFile(path, rows)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch formato {
+	case "csv":
+		w := csv.NewWriter(f)
+		var zero T
+		w.Write(zero.csvHeader())
+		for _, r := range rows {
+			w.Write(r.csvRecord())
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	case "ndjson":
+		enc := json.NewEncoder(f)
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	}
+	return f.Close()
+}
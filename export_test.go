@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestNewEstatisticaRow(t *testing.T) {
+	casos := []struct {
+		v      any
+		valor  string
+		numero *float64
+	}{
+		{42, "42", ptr(42.0)},
+		{2.5, "2.5", ptr(2.5)},
+		{"17", "17", ptr(17.0)},
+		{"ativo", "ativo", nil},
+		{true, "true", nil},
+	}
+	for _, caso := range casos {
+		row := newEstatisticaRow("status", "chave", caso.v)
+		want := estatisticaRow{Origem: "status", Chave: "chave", Valor: caso.valor, Numero: caso.numero}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("newEstatisticaRow(%#v) = %+v, esperado %+v", caso.v, row, want)
+		}
+	}
+}
+
+func TestAppendEstatisticas(t *testing.T) {
+	m := map[string]any{
+		"total_operacoes": 3,
+		"por_operacao":    map[string]any{"soma": 1, "echo": 2},
+		"versao":          "1.0",
+	}
+	rows := appendEstatisticas([]estatisticaRow{newEstatisticaRow("status", "status", "ativo")}, "historico", "", m)
+
+	var got [][2]string
+	for _, r := range rows {
+		got = append(got, [2]string{r.Origem, r.Chave})
+	}
+	want := [][2]string{
+		{"status", "status"},
+		{"historico", "por_operacao.echo"},
+		{"historico", "por_operacao.soma"},
+		{"historico", "total_operacoes"},
+		{"historico", "versao"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("linhas = %v, esperado %v", got, want)
+	}
+	if n := rows[1].Numero; n == nil || *n != 2 {
+		t.Errorf("por_operacao.echo = %v, esperado 2", n)
+	}
+}
+
+func TestEstatisticasHistorico(t *testing.T) {
+	rows := []historicoRow{
+		{Comando: "echo", Sucesso: true},
+		{Comando: "echo", Sucesso: false},
+		{Comando: "soma", Sucesso: true},
+	}
+	want := map[string]any{
+		"total_operacoes": 3,
+		"sucessos":        2,
+		"falhas":          1,
+		"por_operacao":    map[string]any{"echo": 2, "soma": 1},
+	}
+	if got := estatisticasHistorico(rows); !reflect.DeepEqual(got, want) {
+		t.Errorf("estatisticasHistorico = %v, esperado %v", got, want)
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	historico := []historicoRow{
+		{Comando: "echo", Timestamp: &ts, Sucesso: true},
+		{Comando: "soma, com vírgula", Sucesso: false},
+	}
+	estatisticas := []estatisticaRow{
+		newEstatisticaRow("historico", "total_operacoes", 2),
+		newEstatisticaRow("status", "status", "ativo"),
+	}
+
+	casos := []struct {
+		formato string
+		hist    string
+		stats   string
+	}{
+		{
+			"csv",
+			"comando,timestamp,sucesso\n" +
+				"echo,2024-05-01T12:30:00.0000005Z,true\n" +
+				"\"soma, com vírgula\",,false\n",
+			"origem,chave,valor,numero\n" +
+				"historico,total_operacoes,2,2\n" +
+				"status,status,ativo,\n",
+		},
+		{
+			"ndjson",
+			`{"comando":"echo","timestamp":"2024-05-01T12:30:00.0000005Z","sucesso":true}` + "\n" +
+				`{"comando":"soma, com vírgula","timestamp":null,"sucesso":false}` + "\n",
+			`{"origem":"historico","chave":"total_operacoes","valor":"2","numero":2}` + "\n" +
+				`{"origem":"status","chave":"status","valor":"ativo","numero":null}` + "\n",
+		},
+	}
+	for _, caso := range casos {
+		dir := t.TempDir()
+		histPath := filepath.Join(dir, "historico")
+		statsPath := filepath.Join(dir, "estatisticas")
+		if err := writeExport(histPath, caso.formato, historico); err != nil {
+			t.Fatalf("%s: %v", caso.formato, err)
+		}
+		if err := writeExport(statsPath, caso.formato, estatisticas); err != nil {
+			t.Fatalf("%s: %v", caso.formato, err)
+		}
+		for path, want := range map[string]string{histPath: caso.hist, statsPath: caso.stats} {
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("%s %s:\n%s\nesperado:\n%s", caso.formato, filepath.Base(path), got, want)
+			}
+		}
+	}
+}
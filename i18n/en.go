@@ -0,0 +1,267 @@
+package i18n
+
+// ingles traduz as mensagens do código, escritas em português.
+var ingles = map[string]string{
+	"falha ao resolver servidor '%s'":                                   "failed to resolve server '%s'",
+	"falha ao conectar (%s:%s)":                                         "failed to connect (%s:%s)",
+	"circuito aberto para a operação '%s' até %s":                       "circuit open for operation '%s' until %s",
+	"circuito '%s' continua aberto: sonda OpStatus falhou":              "circuit '%s' is still open: OpStatus probe failed",
+	"clocksync: número de amostras deve ser positivo (recebido %d)":     "clocksync: number of samples must be positive (got %d)",
+	"clocksync: amostra %d":                                             "clocksync: sample %d",
+	"protocolo '%s' desconhecido":                                       "unknown protocol '%s'",
+	"%s: falha ao serializar requisição":                                "%s: failed to serialize request",
+	"%s: falha ao enviar mensagem":                                      "%s: failed to send message",
+	"%s: falha ao ler resposta":                                         "%s: failed to read response",
+	"%s: resposta inesperada do codec para a operação '%s': %T":         "%s: unexpected codec response for operation '%s': %T",
+	"erro do servidor: %s":                                              "server error: %s",
+	"mensagem do servidor (%s) excede o limite de %d bytes":             "server message (%s) exceeds the %d byte limit",
+	"mensagem do servidor (%s) de %d bytes excede o limite de %d bytes": "server message (%s) of %d bytes exceeds the %d byte limit",
+	"falha ao resolver servidor":                                        "failed to resolve server",
+	"falha ao conectar":                                                 "failed to connect",
+	"cliente não conectado":                                             "client not connected",
+	"falha ao serializar requisição":                                    "failed to serialize request",
+	"falha ao enviar mensagem":                                          "failed to send message",
+	"falha ao ler resposta":                                             "failed to read response",
+	"requisição inválida":                                               "invalid request",
+	"resposta inválida do servidor":                                     "invalid server response",
+	"operação não suportada":                                            "unsupported operation",
+	"falha na autenticação":                                             "authentication failed",
+	"sessão encerrada":                                                  "session closed",
+	"nenhum servidor disponível":                                        "no server available",
+	"nenhum servidor encontrado":                                        "no server found",
+	"failover: nenhum servidor informado":                               "failover: no server given",
+	"failover: falha ao reautenticar em %s":                             "failover: failed to re-authenticate on %s",
+	"failover: cliente não conectado":                                   "failover: client not connected",
+	"histórico: o servidor repetiu o cursor %q":                         "history: the server repeated cursor %q",
+	"última falha: %v":                                                  "last failure: %v",
+	"interceptor: requisição inválida para a operação '%s': %T":         "interceptor: invalid request for operation '%s': %T",
+	"interceptor: resposta inesperada para a operação '%s': %T":         "interceptor: unexpected response for operation '%s': %T",
+	"[DEBUG %s] Enviando: %s":                                           "[DEBUG %s] Sending: %s",
+	"[DEBUG %s] Recebido: %s":                                           "[DEBUG %s] Received: %s",
+	"falha no logout":                                                   "logout failed",
+	"Falha no 'tipo'=\"info\", tentando 'operacao'=\"info\"...":         "'tipo'=\"info\" failed, trying 'operacao'=\"info\"...",
+	"erro na operação '%s'":                                             "error in operation '%s'",
+	"requisição inválida para a operação '%s': %T":                      "invalid request for operation '%s': %T",
+	"falha ao ler/decodificar resposta %s":                              "failed to read/decode %s response",
+	"falha na autenticação: (status não OK e sem mensagem de erro)":     "authentication failed: (status not OK and no error message)",
+	"falha no logout: (status não OK e sem mensagem de erro)":           "logout failed: (status not OK and no error message)",
+	"operação '%s' não suportada":                                       "operation '%s' not supported",
+	"erro na operação '%s': (status não OK e sem mensagem de erro)":     "error in operation '%s': (status not OK and no error message)",
+	"[Servidor String]: %s":                                             "[String server]: %s",
+	"Campo '%s': %s":                                                    "Field '%s': %s",
+	"proto: falha ao desserializar resposta":                            "proto: failed to deserialize response",
+	"proto: resposta de operação inválida (nula)":                       "proto: invalid operation response (nil)",
+	"proto: operação '%s' não suportada":                                "proto: operation '%s' not supported",
+	"proto: resposta de autenticação inválida (nula)":                   "proto: invalid authentication response (nil)",
+	"proto: falha na autenticação":                                      "proto: authentication failed",
+	"proto: falha na autenticação - sem token retornado":                "proto: authentication failed - no token returned",
+	"proto: operação falhou - sem dados retornados":                     "proto: operation failed - no data returned",
+	"registro %s:%d: esperado 'protocolo host porta [nome]'":            "registry %s:%d: expected 'protocol host port [name]'",
+	"registro %s:%d: porta inválida '%s'":                               "registry %s:%d: invalid port '%s'",
+	"falha na consulta SRV de %s":                                       "SRV lookup for %s failed",
+	"nenhum servidor encontrado para '%s' (protocolo %s)":               "no server found for '%s' (protocol %s)",
+	"sessão: falha ao autenticar":                                       "session: failed to authenticate",
+	"sessão: falha ao reautenticar":                                     "session: failed to re-authenticate",
+	"erro desconhecido":                                                 "unknown error",
+	"resposta de LOGOUT inválida":                                       "invalid LOGOUT response",
+	"resposta vazia ou inválida do servidor":                            "empty or invalid server response",
+	"resposta inesperada do servidor: %s":                               "unexpected server response: %s",
+	"resposta de %s incompleta. Esperado %v campos, recebido %d":        "incomplete %s response. Expected %v fields, got %d",
+	"timestamp do servidor em formato desconhecido: %q":                 "server timestamp in unknown format: %q",
+	"idioma '%s' desconhecido. Use 'pt' ou 'en'":                        "unknown language '%s'. Use 'pt' or 'en'",
+	"Protocolo a ser usado (ou 'all' para String, JSON e Proto em paralelo, ou lista separada por vírgulas)": "Protocol to use (or 'all' for String, JSON and Proto in parallel, or a comma-separated list)",
+	"Matrícula do aluno para teste":                                                       "Student ID used in the test",
+	"Fuso horário para exibição dos timestamps (ex.: America/Fortaleza, UTC)":             "Time zone used to display timestamps (e.g. America/Fortaleza, UTC)",
+	"Novas tentativas em caso de timeout ou falha de conexão":                             "Retries on timeout or connection failure",
+	"Arquivo onde gravar as mensagens trocadas com o servidor":                            "File where messages exchanged with the server are recorded",
+	"Tamanho máximo (bytes) de uma resposta do servidor":                                  "Maximum size (bytes) of a server response",
+	"Estratégia de escolha entre vários servidores: round-robin ou least-latency":         "Strategy for choosing among several servers: round-robin or least-latency",
+	"Arquivo de registro de servidores (linhas 'protocolo host porta [nome]')":            "Server registry file (lines 'protocol host port [name]')",
+	"Resolve -host como domínio DNS com registros SRV _sd-<protocolo>._tcp":               "Resolve -host as a DNS domain with _sd-<protocol>._tcp SRV records",
+	"Intervalo das sondas (Auth, OpStatus e Logout) nos servidores da lista (0 desativa)": "Interval of the probes (Auth, OpStatus and Logout) on the listed servers (0 disables)",
+	"Algoritmos de compressão oferecidos ao servidor (ex.: zstd,gzip,snappy)":             "Compression algorithms offered to the server (e.g. zstd,gzip,snappy)",
+	"Formato da saída: texto (logs), json, tap ou junit":                                  "Output format: texto (logs), json, tap or junit",
+	"Exibe os logs de cada passo quando vários protocolos são testados":                   "Show the logs of each step when several protocols are tested",
+	"Idioma das mensagens: pt ou en (padrão: LANG)":                                       "Message language: pt or en (default: LANG)",
+	"Formato de saída '%s' desconhecido. Use 'texto', 'json', 'tap' ou 'junit'.":          "Unknown output format '%s'. Use 'texto', 'json', 'tap' or 'junit'.",
+	"Iniciando teste com protocolo: %s":                                                   "Starting test with protocol: %s",
+	"fuso horário inválido '%s': %v":                                                      "invalid time zone '%s': %v",
+	"falha ao criar arquivo de captura: %v":                                               "failed to create capture file: %v",
+	"Erro: use apenas um de -registry e -srv":                                             "Error: use only one of -registry and -srv",
+	"falha ao ler registro de servidores: %v":                                             "failed to read server registry: %v",
+	"Estratégia '%s' desconhecida. Use 'round-robin' ou 'least-latency'.":                 "Unknown strategy '%s'. Use 'round-robin' or 'least-latency'.",
+	"\n--- TESTE FALHOU ---\n%v\n--------------------":                                    "\n--- TEST FAILED ---\n%v\n-------------------",
+	"\n--- TESTE CONCLUÍDO COM SUCESSO ---":                                               "\n--- TEST COMPLETED SUCCESSFULLY ---",
+	"falha ao escrever a saída %s: %v":                                                    "failed to write %s output: %v",
+	"Protocolo '%s' desconhecido. Use 'string', 'json', 'proto', 'msgpack' ou 'cbor'.":    "Unknown protocol '%s'. Use 'string', 'json', 'proto', 'msgpack' or 'cbor'.",
+	"[PASSO 1/9] Conectando a %s (protocolo: %s)...":                                      "[STEP 1/9] Connecting to %s (protocol: %s)...",
+	"falha ao conectar: %w":                                                               "failed to connect: %w",
+	"... Conectado a %s.":                                                                 "... Connected to %s.",
+	"... Conectado.":                                                                      "... Connected.",
+	"[PASSO 2/9] Autenticando com ID: %s...":                                              "[STEP 2/9] Authenticating with ID: %s...",
+	"falha no Auth: %w":                                                                   "Auth failed: %w",
+	"... Autenticado: %s (%s)":                                                            "... Authenticated: %s (%s)",
+	"[PASSO 3/9] Testando OpEcho...":                                                      "[STEP 3/9] Testing OpEcho...",
+	"falha no OpEcho: %w":                                                                 "OpEcho failed: %w",
+	"[PASSO 4/9] Testando OpSoma...":                                                      "[STEP 4/9] Testing OpSoma...",
+	"falha no OpSoma: %w":                                                                 "OpSoma failed: %w",
+	"... Soma OK: Soma=%.2f, Média=%.2f, Max=%.2f, Min=%.2f":                              "... Soma OK: Sum=%.2f, Mean=%.2f, Max=%.2f, Min=%.2f",
+	"[PASSO 5/9] Testando OpTimestamp...":                                                 "[STEP 5/9] Testing OpTimestamp...",
+	"falha no OpTimestamp: %w":                                                            "OpTimestamp failed: %w",
+	"... Timestamp OK: %s (%s) | Servidor: %s":                                            "... Timestamp OK: %s (%s) | Server: %s",
+	"[PASSO 6/9] Testando OpStatus (detalhado)...":                                        "[STEP 6/9] Testing OpStatus (detailed)...",
+	"falha no OpStatus: %w":                                                               "OpStatus failed: %w",
+	"... Status OK: %s | Ops Processadas: %d":                                             "... Status OK: %s | Ops processed: %d",
+	"... Estatísticas do Status: %+v":                                                     "... Status statistics: %+v",
+	"[PASSO 7/9] Testando OpHistorico (limite 5)...":                                      "[STEP 7/9] Testing OpHistorico (limit 5)...",
+	"falha no OpHistorico: %w":                                                            "OpHistorico failed: %w",
+	"... Histórico OK: %d operações retornadas.":                                          "... History OK: %d operations returned.",
+	"[PASSO 8/9] Testando Info (detalhado)...":                                            "[STEP 8/9] Testing Info (detailed)...",
+	"falha no Info: %w":                                                                   "Info failed: %w",
+	"... Info OK: Servidor %s | Protocolo %s":                                             "... Info OK: Server %s | Protocol %s",
+	"[PASSO 9/9] Testando Logout...":                                                      "[STEP 9/9] Testing Logout...",
+	"falha no Logout: %w":                                                                 "Logout failed: %w",
+	"passo":                                                                               "step",
+	"FALHOU":                                                                              "FAILED",
+	"resultado":                                                                           "result",
+	"falha no handshake TLS":                                                              "TLS handshake failed",
+	"perfil '%s' não encontrado em %s":                                                    "profile '%s' not found in %s",
+	"valor inválido para -%s em %s: %v":                                                   "invalid value for -%s in %s: %v",
+	"porta inválida '%s': use protocolo=porta":                                            "invalid port '%s': use protocol=port",
+	"Conecta aos servidores com TLS":                                                      "Connect to the servers over TLS",
+	"Certificados de CA (PEM) aceitos, além dos do sistema":                               "CA certificates (PEM) to trust besides the system ones",
+	"Certificado do cliente (PEM), exige -tls-key":                                        "Client certificate (PEM), requires -tls-key",
+	"Chave privada (PEM) de -tls-cert":                                                    "Private key (PEM) of -tls-cert",
+	"Nome esperado no certificado do servidor (padrão: o host)":                           "Name expected in the server certificate (default: the host)",
+	"Não verifica o certificado do servidor":                                              "Do not verify the server certificate",
+	"nenhum certificado PEM em %s":                                                        "no PEM certificate in %s",
+	"Timeout da sequência de testes":                                                      "Timeout of the test sequence",
+	"Portas por protocolo no lugar das padrão (ex.: json=9081,proto=9082)":                "Per-protocol ports replacing the defaults (e.g. json=9081,proto=9082)",
+	"Arquivo de configuração YAML com perfis (padrão: sd.yaml, se existir)":               "YAML configuration file with profiles (default: sd.yaml, if present)",
+	"Perfil do arquivo de configuração (lab, local, prod...)":                             "Profile of the configuration file (lab, local, prod...)",
+	"falha na configuração TLS: %v":                                                       "invalid TLS configuration: %v",
+	"... AVISO: fuso do servidor '%s' desconhecido, horário interpretado em UTC":          "... WARNING: unknown server time zone '%s', time interpreted as UTC",
+	"falha ao gravar a captura em %s: %v":                                                 "failed to write the capture to %s: %v",
+	"IP do servidor (ou lista separada por vírgulas para failover); padrão: o do perfil de configuração ou do -registry": "Server IP (or a comma-separated list for failover); default: the one from the configuration profile or -registry",
+	"Erro: informe o servidor com -host, SD_HOST, um perfil do arquivo de configuração ou -registry":                     "Error: set the server with -host, SD_HOST, a configuration file profile or -registry",
+	"histórico: o servidor devolveu %d de %d operações, sem cursor para continuar":                                       "history: the server returned %d of %d operations, with no cursor to continue",
+	"Protocolo a ser usado (string, json, proto, msgpack ou cbor)":                                                       "Protocol to use (string, json, proto, msgpack or cbor)",
+	"IP do servidor (padrão: o do perfil de configuração ou do -registry)":                                               "Server IP (default: the one from the config profile or -registry)",
+	"Formato dos arquivos: csv, ndjson ou parquet":                                                                       "File format: csv, ndjson or parquet",
+	"Prefixo dos arquivos gerados (<saida>-historico e <saida>-estatisticas)":                                            "Prefix of the generated files (<saida>-historico and <saida>-estatisticas)",
+	"Operações do histórico pedidas por página":                                                                          "History operations requested per page",
+	"Máximo de operações exportadas (0 para todas)":                                                                      "Maximum operations exported (0 for all)",
+	"Exporta só estas operações (lista separada por vírgulas)":                                                           "Export only these operations (comma-separated list)",
+	"Timeout da exportação":                                                                   "Export timeout",
+	"Exibe os logs do cliente":                                                                "Show the client logs",
+	"Formato '%s' desconhecido. Use 'csv', 'ndjson' ou 'parquet'.":                            "Unknown format '%s'. Use 'csv', 'ndjson' or 'parquet'.",
+	"falha ao autenticar: %w":                                                                 "authentication failed: %w",
+	"falha ao ler o histórico: %w":                                                            "failed to read the history: %w",
+	"falha ao gravar %s: %w":                                                                  "failed to write %s: %w",
+	"%d operações em %s\n%d estatísticas em %s\n":                                             "%d operations in %s\n%d statistics in %s\n",
+	"Nenhuma sequência executada.":                                                            "No sequence executed.",
+	"Sequências: %d | Falhas: %d | Tempo total: %v | Vazão: %.2f seq/s":                       "Sequences: %d | Failures: %d | Total time: %v | Throughput: %.2f seq/s",
+	"Latência: min=%v média=%v p50=%v p95=%v max=%v":                                          "Latency: min=%v mean=%v p50=%v p95=%v max=%v",
+	"Bytes por sequência: enviados=%d recebidos=%d":                                           "Bytes per sequence: sent=%d received=%d",
+	"Servidor de métricas encerrado: %v":                                                      "Metrics server stopped: %v",
+	"Métricas disponíveis em http://%s/metrics":                                               "Metrics available at http://%s/metrics",
+	"Interrompe as chamadas de operações com muitas falhas (circuit breaker)":                 "Stop calling operations with too many failures (circuit breaker)",
+	"Máximo de requisições por segundo somando todos os clientes (0 = sem limite)":            "Maximum requests per second across all clients (0 = no limit)",
+	"Rajada máxima permitida pelo limite de vazão":                                            "Maximum burst allowed by the rate limit",
+	"Limite de requisições por segundo por operação (ex.: echo=2,soma=5)":                     "Requests per second limit per operation (e.g. echo=2,soma=5)",
+	"Máximo de requisições simultâneas (0 = sem limite)":                                      "Maximum concurrent requests (0 = no limit)",
+	"Máximo de requisições simultâneas por operação (ex.: historico=1)":                       "Maximum concurrent requests per operation (e.g. historico=1)",
+	"Circuit breaker: operação '%s' agora está %s":                                            "Circuit breaker: operation '%s' is now %s",
+	"limite de vazão inválido para '%s': %s":                                                  "invalid rate limit for '%s': %s",
+	"limite de simultaneidade inválido para '%s': %s":                                         "invalid concurrency limit for '%s': %s",
+	"Protocolo a ser usado":                                                                   "Protocol to use",
+	"Número total de sequências de teste":                                                     "Total number of test sequences",
+	"Número de clientes concorrentes":                                                         "Number of concurrent clients",
+	"Timeout de cada sequência":                                                               "Timeout of each sequence",
+	"Endereço do endpoint /metrics (vazio desativa)":                                          "Address of the /metrics endpoint (empty disables it)",
+	"Exibe os logs de cada sequência":                                                         "Show the logs of each sequence",
+	"Sequência falhou: %v":                                                                    "Sequence failed: %v",
+	"Duração total do teste de resistência":                                                   "Total duration of the soak test",
+	"Intervalo entre sequências":                                                              "Interval between sequences",
+	"Número de trocas de timestamp":                                                           "Number of timestamp exchanges",
+	"Intervalo entre amostras":                                                                "Interval between samples",
+	"\n--- CLOCKSYNC FALHOU ---\n%v\n------------------------":                                "\n--- CLOCKSYNC FAILED ---\n%v\n------------------------",
+	"Coletando %d amostras de OpTimestamp...":                                                 "Collecting %d OpTimestamp samples...",
+	"Amostras válidas: %d | Descartadas (outliers): %d | RTT mediano: %v":                     "Valid samples: %d | Discarded (outliers): %d | Median RTT: %v",
+	"Melhor estimativa: Offset=%v ± %v (RTT=%v)":                                              "Best estimate: Offset=%v ± %v (RTT=%v)",
+	"Offset médio (amostras válidas): %v":                                                     "Mean offset (valid samples): %v",
+	"Protocolo a ser usado (json, proto, msgpack ou cbor)":                                    "Protocol to use (json, proto, msgpack or cbor)",
+	"IP do servidor (ex.: o de 'go run . serve')":                                             "Server IP (e.g. the one from 'go run . serve')",
+	"Tamanho (bytes) da mensagem enviada no OpEcho":                                           "Size (bytes) of the message sent in OpEcho",
+	"Número de OpEcho/OpHistorico por algoritmo":                                              "Number of OpEcho/OpHistorico calls per algorithm",
+	"Algoritmos comparados":                                                                   "Algorithms compared",
+	"Timeout de cada rodada":                                                                  "Timeout of each round",
+	"Exibe os logs dos clientes":                                                              "Show the client logs",
+	"Rodada '%s' falhou: %v":                                                                  "Round '%s' failed: %v",
+	"--- COMPRESSÃO %s (OpEcho de %d bytes e OpHistorico, %d vezes) ---\n":                    "--- COMPRESSION %s (OpEcho of %d bytes and OpHistorico, %d times) ---\n",
+	"algoritmo\tnegociado\tenviados\trecebidos\ttotal\tvs. primeiro\ttempo\t":                 "algorithm\tnegotiated\tsent\treceived\ttotal\tvs. first\ttime\t",
+	"o protocolo '%s' não suporta compressão":                                                 "protocol '%s' does not support compression",
+	"Protocolo do fluxo capturado (string, json, proto, msgpack ou cbor)":                     "Protocol of the captured stream (string, json, proto, msgpack or cbor)",
+	"Arquivo com o fluxo TCP ('-' para a entrada padrão)":                                     "File with the TCP stream ('-' for standard input)",
+	"Formato do arquivo: auto, bin, hex ou captura":                                           "File format: auto, bin, hex or captura",
+	"Direção do fluxo protobuf: auto, req ou resp":                                            "Direction of the protobuf stream: auto, req or resp",
+	"falha ao abrir %s: %v":                                                                   "failed to open %s: %v",
+	"falha ao ler entrada: %v":                                                                "failed to read input: %v",
+	"--- #%d %s sessão %d %s (%s) ---\n":                                                      "--- #%d %s session %d %s (%s) ---\n",
+	"  erro: %v\n":                                                                            "  error: %v\n",
+	"formato '%s' desconhecido. Use auto, bin, hex ou captura":                                "unknown format '%s'. Use auto, bin, hex or captura",
+	"Protocolo a intermediar (string, json, proto, msgpack, cbor ou all)":                     "Protocol to proxy (string, json, proto, msgpack, cbor or all)",
+	"IP do servidor real":                                                                     "IP of the real server",
+	"Endereço onde escutar":                                                                   "Address to listen on",
+	"Atraso aplicado a cada resposta":                                                         "Delay applied to each response",
+	"Probabilidade (0-1) de descartar uma resposta":                                           "Probability (0-1) of dropping a response",
+	"Probabilidade (0-1) de truncar uma resposta e encerrar a conexão":                        "Probability (0-1) of truncating a response and closing the connection",
+	"Probabilidade (0-1) de corromper o cabeçalho de tamanho (protobuf, msgpack e cbor)":      "Probability (0-1) of corrupting the length header (protobuf, msgpack and cbor)",
+	"Campos a reescrever nas respostas (ex.: status=FORA,soma=42)":                            "Fields to rewrite in the responses (e.g. status=FORA,soma=42)",
+	"Semente do sorteio das falhas":                                                           "Seed for drawing the faults",
+	"Protocolo '%s' desconhecido. Use 'string', 'json', 'proto', 'msgpack', 'cbor' ou 'all'.": "Unknown protocol '%s'. Use 'string', 'json', 'proto', 'msgpack', 'cbor' or 'all'.",
+	"falha ao escutar em %s:%s: %v":                                                           "failed to listen on %s:%s: %v",
+	"proxy %s encerrado: %v":                                                                  "proxy %s stopped: %v",
+	"Arquivo de captura gravado com -capture":                                                 "Capture file recorded with -capture",
+	"Erro: informe o arquivo de captura com -file=[ARQUIVO]":                                  "Error: set the capture file with -file=[FILE]",
+	"falha ao ler captura: %v":                                                                "failed to read capture: %v",
+	"Reproduzindo captura %s em %s (protocolo: %s)":                                           "Replaying capture %s on %s (protocol: %s)",
+	"replay %s encerrado: %v":                                                                 "replay %s stopped: %v",
+	"Erro: a captura %s não contém respostas gravadas":                                        "Error: capture %s has no recorded responses",
+	"Protocolo a servir (string, json, proto, msgpack, cbor ou all)":                          "Protocol to serve (string, json, proto, msgpack, cbor or all)",
+	"Algoritmos de compressão aceitos, em ordem de preferência (none desativa)":               "Accepted compression algorithms, in order of preference (none disables it)",
+	"Certificado (PEM) para servir com TLS; exige -tls-key":                                   "Certificate (PEM) to serve with TLS; requires -tls-key",
+	"Chave privada (PEM) do certificado de -tls-cert":                                         "Private key (PEM) of the -tls-cert certificate",
+	"falha ao carregar o certificado TLS: %v":                                                 "failed to load the TLS certificate: %v",
+	"Servidor de teste %s em %s":                                                              "Test server %s on %s",
+	"servidor %s encerrado: %v":                                                               "server %s stopped: %v",
+	"perfil '%s' pedido, mas nenhum arquivo de configuração foi encontrado (%s)":              "profile '%s' requested, but no configuration file was found (%s)",
+	"Erro: informe o servidor real com -upstream=[IP]":                                        "Error: set the real server with -upstream=[IP]",
+	"proto: histórico em formato desconhecido: %s":                                            "proto: history in an unknown format: %s",
+	"... Echo OK: Hash %s":                                                                    "... Echo OK: Hash %s",
+	"... Logout OK.":                                                                          "... Logout OK.",
+	"total":                                                                                   "total",
+	"(mensagem sem terminador FIM)":                                                           "(message without the FIM terminator)",
+	"[comprimido com %s: %d bytes -> %d bytes]":                                               "[compressed with %s: %d bytes -> %d bytes]",
+	"[cabeçalho: %d bytes | payload: %d bytes]":                                               "[header: %d bytes | payload: %d bytes]",
+	"[comprimido com %s: %d bytes descomprimidos]":                                            "[compressed with %s: %d bytes decompressed]",
+	"[compressão negociada: %s]":                                                              "[negotiated compression: %s]",
+	"(payload truncado)":                                                                      "(truncated payload)",
+	"(%d bytes de campos desconhecidos)":                                                      "(%d bytes of unknown fields)",
+	"erro: %v":                                                                                "error: %v",
+	"cliente -> servidor":                                                                     "client -> server",
+	"servidor -> cliente":                                                                     "server -> client",
+	"falha ao conectar ao servidor %s: %v":                                                    "failed to connect to server %s: %v",
+	"conexão %s -> %s":                                                                        "connection %s -> %s",
+	"falha: atrasando resposta em %v":                                                         "fault: delaying the response by %v",
+	"falha: resposta descartada":                                                              "fault: response dropped",
+	"falha ao reescrever resposta: %v":                                                        "failed to rewrite the response: %v",
+	"falha: cabeçalho de tamanho corrompido para %d":                                          "fault: length header corrupted to %d",
+	"falha: resposta truncada em %d de %d bytes; encerrando conexão":                          "fault: response truncated at %d of %d bytes; closing the connection",
+	"requisição %d sem resposta gravada; encerrando conexão":                                  "request %d has no recorded response; closing the connection",
+	"falha ao responder: %v":                                                                  "failed to respond: %v",
+	"Proxy %s: %s -> %s":                                                                      "Proxy %s: %s -> %s",
+	"[%s] requisição: %+v":                                                                    "[%s] request: %+v",
+	"[%s] erro: %v":                                                                           "[%s] error: %v",
+	"[%s] resposta: %+v":                                                                      "[%s] response: %+v",
+}
@@ -0,0 +1,82 @@
+// Package i18n traduz as mensagens da CLI e os erros do cliente. As mensagens
+// são escritas em português no código e servem de chave para os catálogos dos
+// outros idiomas; uma mensagem sem tradução aparece em português.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+type Idioma string
+
+const (
+	Portugues Idioma = "pt"
+	Ingles    Idioma = "en"
+)
+
+var catalogos = map[Idioma]map[string]string{
+	Portugues: nil,
+	Ingles:    ingles,
+}
+
+var atual atomic.Value
+
+func init() {
+	atual.Store(Portugues)
+}
+
+// Parse interpreta um idioma como "en", "pt_BR" ou "en_US.UTF-8".
+func Parse(s string) (Idioma, error) {
+	base, _, _ := strings.Cut(s, ".")
+	base, _, _ = strings.Cut(base, "_")
+	base, _, _ = strings.Cut(base, "-")
+	idioma := Idioma(strings.ToLower(base))
+	if _, ok := catalogos[idioma]; !ok {
+		return "", errors.New(T("idioma '%s' desconhecido. Use 'pt' ou 'en'", s))
+	}
+	return idioma, nil
+}
+
+// Set define o idioma das mensagens.
+func Set(s string) error {
+	idioma, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	atual.Store(idioma)
+	return nil
+}
+
+// SetFromEnv usa o idioma de LC_ALL, LC_MESSAGES ou LANG, nessa ordem, e
+// mantém o atual se nenhum for conhecido.
+func SetFromEnv() {
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if s := os.Getenv(v); s != "" {
+			// "C" e "POSIX" não escolhem idioma; os demais valores valem
+			// mesmo que desconhecidos, como no gettext.
+			if s != "C" && s != "POSIX" {
+				Set(s)
+			}
+			return
+		}
+	}
+}
+
+func Atual() Idioma {
+	return atual.Load().(Idioma)
+}
+
+// T traduz msg para o idioma atual e formata com args, como fmt.Sprintf.
+func T(msg string, args ...any) string {
+	if t, ok := catalogos[Atual()][msg]; ok {
+		msg = t
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
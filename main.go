@@ -4,117 +4,347 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/GuilhermeGalvao1/SD-trab1/client"
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
 )
 
+var commands = map[string]func(args []string){
+	"clocksync": runClockSyncCommand,
+	"bench":     runBenchCommand,
+	"soak":      runSoakCommand,
+	"replay":    runReplayCommand,
+	"decode":    runDecodeCommand,
+	"proxy":     runProxyCommand,
+	"serve":     runServeCommand,
+	"compress":  runCompressCommand,
+	"export":    runExportCommand,
+}
+
 func main() {
-	proto := flag.String("proto", "json", "Protocolo a ser usado")
-	host := flag.String("host", "3.88.99.255", "IP do servidor")
-	id := flag.String("id", "520402", "Matrícula do aluno para teste")
+	i18n.SetFromEnv()
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	proto := flag.String("proto", "json", i18n.T("Protocolo a ser usado (ou 'all' para String, JSON e Proto em paralelo, ou lista separada por vírgulas)"))
+	tz := flag.String("tz", "Local", i18n.T("Fuso horário para exibição dos timestamps (ex.: America/Fortaleza, UTC)"))
+	retry := flag.Int("retry", 0, i18n.T("Novas tentativas em caso de timeout ou falha de conexão"))
+	capture := flag.String("capture", "", i18n.T("Arquivo onde gravar as mensagens trocadas com o servidor"))
+	balance := flag.String("balance", string(client.RoundRobin), i18n.T("Estratégia de escolha entre vários servidores: round-robin ou least-latency"))
+	probe := flag.Duration("probe", 0, i18n.T("Intervalo das sondas (Auth, OpStatus e Logout) nos servidores da lista (0 desativa)"))
+	compress := flag.String("compress", "", i18n.T("Algoritmos de compressão oferecidos ao servidor (ex.: zstd,gzip,snappy)"))
+	output := flag.String("output", "texto", i18n.T("Formato da saída: texto (logs), json, tap ou junit"))
+	verbose := flag.Bool("v", false, i18n.T("Exibe os logs de cada passo quando vários protocolos são testados"))
+	timeout := flag.Duration("timeout", 60*time.Second, i18n.T("Timeout da sequência de testes"))
+	server := addServerFlags(flag.CommandLine, i18n.T("IP do servidor (ou lista separada por vírgulas para failover); padrão: o do perfil de configuração ou do -registry"))
 	flag.Parse()
 
-	if *host == "" {
-		log.Fatalf("Erro: O IP do host é obrigatório. Use -host=[IP_PUBLICO]")
+	if err := server.setup(flag.CommandLine); err != nil {
+		log.Fatal(err)
+	}
+
+	writeReport, ok := outputFormats[*output]
+	if !ok && *output != "texto" {
+		log.Fatalf(i18n.T("Formato de saída '%s' desconhecido. Use 'texto', 'json', 'tap' ou 'junit'."), *output)
+	}
+
+	log.Printf(i18n.T("Iniciando teste com protocolo: %s"), *proto)
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		log.Fatalf(i18n.T("fuso horário inválido '%s': %v"), *tz, err)
+	}
+	var recorder *wire.Recorder
+	if *capture != "" {
+		f, err := os.Create(*capture)
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao criar arquivo de captura: %v"), err)
+		}
+		recorder = wire.NewRecorder(f)
+	}
+
+	compressoes, err := wire.ParseCompressoes(*compress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	newConfiguredClient := func(proto string) (client.Client, error) {
+		c, err := newClient(proto)
+		if err != nil {
+			return nil, err
+		}
+		if lc, ok := c.(interface{ SetDisplayLocation(*time.Location) }); ok {
+			lc.SetDisplayLocation(loc)
+		}
+		server.configure(c, proto)
+		if cc, ok := c.(interface{ SetCapture(*wire.Recorder) }); ok && recorder != nil {
+			cc.SetCapture(recorder)
+		}
+		if cc, ok := c.(interface{ SetCompression(...string) error }); ok && len(compressoes) > 0 {
+			if err := cc.SetCompression(compressoes...); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
 	}
 
-	var c client.Client
-	log.Printf("Iniciando teste com protocolo: %s\n", *proto)
+	failover := strings.Contains(*server.host, ",")
+	var failoverHosts []string
+	for _, h := range strings.Split(*server.host, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			failoverHosts = append(failoverHosts, h)
+		}
+	}
+	strategy := client.Strategy(*balance)
+	if failover && strategy != client.RoundRobin && strategy != client.LeastLatency {
+		log.Fatalf(i18n.T("Estratégia '%s' desconhecida. Use 'round-robin' ou 'least-latency'."), *balance)
+	}
+	probeCtx, stopProbe := context.WithCancel(context.Background())
+	defer stopProbe()
 
-	switch *proto {
-	case "string":
-		c = client.NewStringClient()
-	case "json":
-		c = client.NewJsonClient()
-	case "proto":
-		c = client.NewProtoClient()
-	default:
-		log.Fatalf("Protocolo '%s' desconhecido. Use 'string', 'json' ou 'proto'.", *proto)
+	protos := []string{*proto}
+	switch {
+	case *proto == "all":
+		protos = allProtocols
+	case strings.Contains(*proto, ","):
+		protos = strings.Split(*proto, ",")
+	}
+	clients := make([]client.Client, len(protos))
+	for i, p := range protos {
+		c, err := newConfiguredClient(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if failover {
+			// O protocolo já foi validado acima, então a fábrica não falha.
+			fc := client.NewFailoverClient(func() client.Client {
+				c, _ := newConfiguredClient(p)
+				return c
+			}, strategy, failoverHosts...)
+			fc.ProbeAluno = *server.id
+			if *probe > 0 {
+				fc.StartHealthChecks(probeCtx, *probe)
+			}
+			c = fc
+		}
+		if *retry > 0 {
+			c = client.WithInterceptors(c, client.RetryInterceptor(*retry+1, 500*time.Millisecond, nil))
+		}
+		clients[i] = c
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	err := runTestSequence(ctx, c, *host, *id, *proto)
+	if len(protos) == 1 && writeReport == nil {
+		_, err := runTestSequence(ctx, clients[0], *server.host, *server.id, *proto)
+		capErr := closeCapture(recorder)
+		if capErr != nil {
+			log.Printf(i18n.T("falha ao gravar a captura em %s: %v"), *capture, capErr)
+		}
+		if err != nil {
+			log.Fatalf(i18n.T("\n--- TESTE FALHOU ---\n%v\n--------------------"), err)
+		}
+		if capErr != nil {
+			os.Exit(1)
+		}
+		log.Println(i18n.T("\n--- TESTE CONCLUÍDO COM SUCESSO ---"))
+		return
+	}
+
+	// Com vários protocolos os logs de cada sequência se misturariam, então
+	// só aparecem com -v. Os erros depois das sequências voltam a aparecer.
+	logs := log.Writer()
+	if len(protos) > 1 && !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	rels := make([]relatorio, len(protos))
+	var wg sync.WaitGroup
+	for i, p := range protos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inicio := time.Now()
+			passos, err := runTestSequence(ctx, clients[i], *server.host, *server.id, p)
+			rels[i] = relatorio{Protocolo: p, Host: *server.host, Inicio: inicio, Duracao: time.Since(inicio), Passos: passos}
+			if err != nil {
+				rels[i].Erro = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	log.SetOutput(logs)
+
+	capErr := closeCapture(recorder)
+	if capErr != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("falha ao gravar a captura em %s: %v", *capture, capErr))
+	}
+	if writeReport == nil {
+		writeReport = writeSummaryTable
+	}
+	if err := writeReport(os.Stdout, rels); err != nil {
+		log.Fatalf(i18n.T("falha ao escrever a saída %s: %v"), *output, err)
+	}
+	for _, r := range rels {
+		if !r.Sucesso() {
+			os.Exit(1)
+		}
+	}
+	if capErr != nil {
+		os.Exit(1)
+	}
+}
+
+// closeCapture fecha o arquivo de -capture, se houver, e devolve a primeira
+// falha ao gravá-lo.
+func closeCapture(r *wire.Recorder) error {
+	if r == nil {
+		return nil
+	}
+	return r.Close()
+}
+
+// allProtocols são os protocolos testados com -proto=all: os atendidos pelo
+// servidor da disciplina.
+var allProtocols = []string{"string", "json", "proto"}
+
+func newClient(proto string) (client.Client, error) {
+	c, err := client.NewProtocolClient(proto)
 	if err != nil {
-		log.Fatalf("\n--- TESTE FALHOU ---\n%v\n--------------------", err)
+		return nil, fmt.Errorf(i18n.T("Protocolo '%s' desconhecido. Use 'string', 'json', 'proto', 'msgpack' ou 'cbor'."), proto)
+	}
+	return c, nil
+}
+
+// activeHost devolve o servidor em uso por um client.FailoverClient, mesmo
+// quando ele está embrulhado por interceptores (-retry).
+func activeHost(c client.Client) (string, bool) {
+	for {
+		switch v := c.(type) {
+		case interface{ Host() string }:
+			return v.Host(), true
+		case interface{ Unwrap() client.Client }:
+			c = v.Unwrap()
+		default:
+			return "", false
+		}
 	}
+}
 
-	log.Println("\n--- TESTE CONCLUÍDO COM SUCESSO ---")
+// runTestSequence executa a sequência de testes e devolve o resultado de cada
+// passo executado, inclusive o que falhou.
+func runTestSequence(ctx context.Context, c client.Client, host, alunoID, protoName string) ([]passoResultado, error) {
+	var seq sequencia
+	err := testSequence(ctx, &seq, c, host, alunoID, protoName)
+	return seq.passos, err
 }
-func runTestSequence(ctx context.Context, c client.Client, host, alunoID, protoName string) error {
-	var token string
 
-	log.Printf("[PASSO 1/9] Conectando a %s (protocolo: %s)...", host, protoName)
+func testSequence(ctx context.Context, seq *sequencia, c client.Client, host, alunoID, protoName string) error {
+	log.Printf(i18n.T("[PASSO 1/9] Conectando a %s (protocolo: %s)..."), host, protoName)
+	seq.inicia("Connect")
 	if err := c.Connect(ctx, host); err != nil {
-		return fmt.Errorf("falha ao conectar: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha ao conectar: %w"), err))
+	}
+	if atual, ok := activeHost(c); ok {
+		seq.ok(map[string]any{"host": atual})
+		log.Printf(i18n.T("... Conectado a %s."), atual)
+	} else {
+		seq.ok(map[string]any{"host": host})
+		log.Println(i18n.T("... Conectado."))
 	}
-	defer c.Disconnect()
-	log.Println("... Conectado.")
 
-	log.Printf("[PASSO 2/9] Autenticando com ID: %s...", alunoID)
-	authResp, err := c.Auth(ctx, alunoID)
+	log.Printf(i18n.T("[PASSO 2/9] Autenticando com ID: %s..."), alunoID)
+	seq.inicia("Auth")
+	s, err := client.Login(ctx, c, alunoID)
 	if err != nil {
-		return fmt.Errorf("falha no Auth: %w", err)
+		c.Disconnect()
+		return seq.falha(fmt.Errorf(i18n.T("falha no Auth: %w"), err))
 	}
-	token = authResp.Token
-	log.Printf("... Autenticado: %s (%s)", authResp.Nome, authResp.Matricula)
+	defer s.Close()
+	authResp := s.Aluno()
+	seq.ok(map[string]any{"nome": authResp.Nome, "matricula": authResp.Matricula})
+	log.Printf(i18n.T("... Autenticado: %s (%s)"), authResp.Nome, authResp.Matricula)
 
-	log.Println("[PASSO 3/9] Testando OpEcho...")
+	log.Println(i18n.T("[PASSO 3/9] Testando OpEcho..."))
+	seq.inicia("OpEcho")
 	echoMsg := "Ola-Mundo-SD-Go"
-	echoResp, err := c.OpEcho(ctx, token, echoMsg)
+	echoResp, err := s.Echo(ctx, echoMsg)
 	if err != nil {
-		return fmt.Errorf("falha no OpEcho: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no OpEcho: %w"), err))
 	}
-	log.Printf("... Echo OK: Hash %s", echoResp.HashMD5)
+	seq.ok(map[string]any{"eco": echoResp.Eco, "tamanho": echoResp.Tamanho, "hash_md5": echoResp.HashMD5})
+	log.Printf(i18n.T("... Echo OK: Hash %s"), echoResp.HashMD5)
 
-	log.Println("[PASSO 4/9] Testando OpSoma...")
-	numeros := []string{"1", "2", "3"}
-	somaResp, err := c.OpSoma(ctx, token, numeros)
+	log.Println(i18n.T("[PASSO 4/9] Testando OpSoma..."))
+	seq.inicia("OpSoma")
+	somaResp, err := s.Soma(ctx, "1", "2", "3")
 	if err != nil {
-		return fmt.Errorf("falha no OpSoma: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no OpSoma: %w"), err))
 	}
-	log.Printf("... Soma OK: Soma=%.2f, Média=%.2f, Max=%.2f, Min=%.2f",
+	seq.ok(map[string]any{"soma": somaResp.Soma, "media": somaResp.Media, "maximo": somaResp.Maximo, "minimo": somaResp.Minimo})
+	log.Printf(i18n.T("... Soma OK: Soma=%.2f, Média=%.2f, Max=%.2f, Min=%.2f"),
 		somaResp.Soma, somaResp.Media, somaResp.Maximo, somaResp.Minimo)
 
-	log.Println("[PASSO 5/9] Testando OpTimestamp...")
-	tsResp, err := c.OpTimestamp(ctx, token)
+	log.Println(i18n.T("[PASSO 5/9] Testando OpTimestamp..."))
+	seq.inicia("OpTimestamp")
+	tsResp, err := s.Timestamp(ctx)
 	if err != nil {
-		return fmt.Errorf("falha no OpTimestamp: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no OpTimestamp: %w"), err))
+	}
+	seq.ok(map[string]any{"timestamp": tsResp.Horario, "timezone": tsResp.Timezone, "timezone_servidor": tsResp.TimezoneServidor, "fuso_desconhecido": tsResp.FusoDesconhecido})
+	log.Printf(i18n.T("... Timestamp OK: %s (%s) | Servidor: %s"),
+		tsResp.TimestampFormatado, tsResp.Timezone, tsResp.TimezoneServidor)
+	if tsResp.FusoDesconhecido {
+		log.Printf(i18n.T("... AVISO: fuso do servidor '%s' desconhecido, horário interpretado em UTC"), tsResp.TimezoneServidor)
 	}
-	log.Printf("... Timestamp OK: %s (%s)", tsResp.TimestampFormatado, tsResp.Timezone)
 
-	log.Println("[PASSO 6/9] Testando OpStatus (detalhado)...")
-	statusResp, err := c.OpStatus(ctx, token, true)
+	log.Println(i18n.T("[PASSO 6/9] Testando OpStatus (detalhado)..."))
+	seq.inicia("OpStatus")
+	statusResp, err := s.Status(ctx, client.WithDetail())
 	if err != nil {
-		return fmt.Errorf("falha no OpStatus: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no OpStatus: %w"), err))
 	}
-	log.Printf("... Status OK: %s | Ops Processadas: %d",
+	seq.ok(map[string]any{"status": statusResp.Status, "operacoes_processadas": statusResp.OperacoesProcessadas, "estatisticas": statusResp.Estatisticas.Map()})
+	log.Printf(i18n.T("... Status OK: %s | Ops Processadas: %d"),
 		statusResp.Status, statusResp.OperacoesProcessadas)
-	if statusResp.Estatisticas != nil {
-		log.Printf("... Estatísticas do Status: %v", statusResp.Estatisticas)
+	if !statusResp.Estatisticas.IsZero() {
+		log.Printf(i18n.T("... Estatísticas do Status: %+v"), statusResp.Estatisticas)
 	}
 
-	log.Println("[PASSO 7/9] Testando OpHistorico (limite 5)...")
-	histResp, err := c.OpHistorico(ctx, token, 5)
+	log.Println(i18n.T("[PASSO 7/9] Testando OpHistorico (limite 5)..."))
+	seq.inicia("OpHistorico")
+	histResp, err := s.Historico(ctx, client.Limit(5))
 	if err != nil {
-		return fmt.Errorf("falha no OpHistorico: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no OpHistorico: %w"), err))
 	}
-	log.Printf("... Histórico OK: %d operações retornadas.", len(histResp.Operacoes))
+	seq.ok(map[string]any{"operacoes": len(histResp.Operacoes), "estatisticas": histResp.Estatisticas.Map()})
+	log.Printf(i18n.T("... Histórico OK: %d operações retornadas."), len(histResp.Operacoes))
 
-	log.Println("[PASSO 8/9] Testando Info (detalhado)...")
-	infoResp, err := c.Info(ctx, token, "detalhado")
+	log.Println(i18n.T("[PASSO 8/9] Testando Info (detalhado)..."))
+	seq.inicia("Info")
+	infoResp, err := s.Info(ctx, "detalhado")
 	if err != nil {
-		return fmt.Errorf("falha no Info: %w", err)
+		return seq.falha(fmt.Errorf(i18n.T("falha no Info: %w"), err))
 	}
-	log.Printf("... Info OK: Servidor %s | Protocolo %s",
+	seq.ok(map[string]any{"servidor": infoResp.DescricaoServidor, "protocolo": infoResp.ProtocoloAtivo, "capacidades": infoResp.Capacidades})
+	log.Printf(i18n.T("... Info OK: Servidor %s | Protocolo %s"),
 		infoResp.DescricaoServidor, infoResp.ProtocoloAtivo)
 
-	log.Println("[PASSO 9/9] Testando Logout...")
-	if err := c.Logout(ctx, token); err != nil {
-		return fmt.Errorf("falha no Logout: %w", err)
+	log.Println(i18n.T("[PASSO 9/9] Testando Logout..."))
+	seq.inicia("Logout")
+	if err := s.Logout(ctx); err != nil {
+		return seq.falha(fmt.Errorf(i18n.T("falha no Logout: %w"), err))
 	}
-	log.Println("... Logout OK.")
+	seq.ok(nil)
+	log.Println(i18n.T("... Logout OK."))
 	return nil
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+func runProxyCommand(args []string) {
+	fs := flag.NewFlagSet("proxy", flag.ExitOnError)
+	proto := fs.String("proto", "all", i18n.T("Protocolo a intermediar (string, json, proto, msgpack, cbor ou all)"))
+	upstream := fs.String("upstream", "", i18n.T("IP do servidor real"))
+	addr := fs.String("addr", "127.0.0.1", i18n.T("Endereço onde escutar"))
+	atraso := fs.Duration("atraso", 0, i18n.T("Atraso aplicado a cada resposta"))
+	descartar := fs.Float64("descartar", 0, i18n.T("Probabilidade (0-1) de descartar uma resposta"))
+	truncar := fs.Float64("truncar", 0, i18n.T("Probabilidade (0-1) de truncar uma resposta e encerrar a conexão"))
+	corromper := fs.Float64("corromper", 0, i18n.T("Probabilidade (0-1) de corromper o cabeçalho de tamanho (protobuf, msgpack e cbor)"))
+	reescrever := fs.String("reescrever", "", i18n.T("Campos a reescrever nas respostas (ex.: status=FORA,soma=42)"))
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), i18n.T("Semente do sorteio das falhas"))
+	lang := addLangFlag(fs)
+	fs.Parse(args)
+
+	if err := setLang(*lang); err != nil {
+		log.Fatal(err)
+	}
+	if *upstream == "" {
+		log.Fatal(i18n.T("Erro: informe o servidor real com -upstream=[IP]"))
+	}
+
+	faults := wire.Faults{
+		Atraso:           *atraso,
+		Descartar:        *descartar,
+		Truncar:          *truncar,
+		CorromperTamanho: *corromper,
+		Reescrever:       parseKeyValues(*reescrever),
+	}
+
+	protos := []string{*proto}
+	if *proto == "all" {
+		protos = []string{"string", "json", "proto", "msgpack", "cbor"}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, p := range protos {
+		port, ok := wire.Ports[p]
+		if !ok {
+			log.Fatalf(i18n.T("Protocolo '%s' desconhecido. Use 'string', 'json', 'proto', 'msgpack', 'cbor' ou 'all'."), p)
+		}
+		px, err := wire.NewProxy(p, net.JoinHostPort(*upstream, port), faults, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(*addr, port))
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao escutar em %s:%s: %v"), *addr, port, err)
+		}
+		log.Printf(i18n.T("Proxy %s: %s -> %s"), p, ln.Addr(), px.Destino)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := px.Serve(ctx, ln); err != nil {
+				log.Printf(i18n.T("proxy %s encerrado: %v"), p, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func parseKeyValues(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	kv := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			kv[strings.TrimSpace(k)] = v
+		}
+	}
+	return kv
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+func runReplayCommand(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	file := fs.String("file", "", i18n.T("Arquivo de captura gravado com -capture"))
+	addr := fs.String("addr", "127.0.0.1", i18n.T("Endereço onde escutar"))
+	lang := addLangFlag(fs)
+	fs.Parse(args)
+
+	if err := setLang(*lang); err != nil {
+		log.Fatal(err)
+	}
+
+	if *file == "" {
+		log.Fatal(i18n.T("Erro: informe o arquivo de captura com -file=[ARQUIVO]"))
+	}
+	frames, err := wire.ReadCaptureFile(*file)
+	if err != nil {
+		log.Fatalf(i18n.T("falha ao ler captura: %v"), err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	servidores := 0
+	for proto, port := range wire.Ports {
+		srv, err := wire.NewReplayServer(frames, proto)
+		if err != nil {
+			continue
+		}
+		servidores++
+		ln, err := net.Listen("tcp", net.JoinHostPort(*addr, port))
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao escutar em %s:%s: %v"), *addr, port, err)
+		}
+		log.Printf(i18n.T("Reproduzindo captura %s em %s (protocolo: %s)"), *file, ln.Addr(), proto)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Serve(ctx, ln); err != nil {
+				log.Printf(i18n.T("replay %s encerrado: %v"), proto, err)
+			}
+		}()
+	}
+	if servidores == 0 {
+		log.Fatalf(i18n.T("Erro: a captura %s não contém respostas gravadas"), *file)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+// passoResultado é o resultado de um passo da sequência de testes. Campos
+// guarda os principais campos da resposta.
+type passoResultado struct {
+	Operacao string
+	Duracao  time.Duration
+	Sucesso  bool
+	Campos   map[string]any
+	Erro     string
+}
+
+// sequencia acompanha os passos de runTestSequence: inicia abre um passo, que
+// é encerrado por ok ou falha.
+type sequencia struct {
+	passos []passoResultado
+	inicio time.Time
+}
+
+func (s *sequencia) inicia(op string) {
+	s.passos = append(s.passos, passoResultado{Operacao: op})
+	s.inicio = time.Now()
+}
+
+func (s *sequencia) ok(campos map[string]any) {
+	p := &s.passos[len(s.passos)-1]
+	p.Duracao = time.Since(s.inicio)
+	p.Sucesso = true
+	p.Campos = campos
+}
+
+func (s *sequencia) falha(err error) error {
+	p := &s.passos[len(s.passos)-1]
+	p.Duracao = time.Since(s.inicio)
+	p.Erro = err.Error()
+	return err
+}
+
+// relatorio é a execução da sequência de testes com um protocolo.
+type relatorio struct {
+	Protocolo string
+	Host      string
+	Inicio    time.Time
+	Duracao   time.Duration
+	Passos    []passoResultado
+	Erro      string
+}
+
+func (r relatorio) Sucesso() bool {
+	return r.Erro == ""
+}
+
+func (r relatorio) falhas() int {
+	n := 0
+	for _, p := range r.Passos {
+		if !p.Sucesso {
+			n++
+		}
+	}
+	return n
+}
+
+func milissegundos(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// outputFormats são os formatos estruturados de -output; o padrão, "texto",
+// mantém só os logs.
+var outputFormats = map[string]func(io.Writer, []relatorio) error{
+	"json":  writeJSONReport,
+	"tap":   writeTAPReport,
+	"junit": writeJUnitReport,
+}
+
+type jsonPasso struct {
+	Passo     int            `json:"passo"`
+	Operacao  string         `json:"operacao"`
+	DuracaoMs float64        `json:"duracao_ms"`
+	Sucesso   bool           `json:"sucesso"`
+	Campos    map[string]any `json:"campos,omitempty"`
+	Erro      string         `json:"erro,omitempty"`
+}
+
+type jsonRelatorio struct {
+	Protocolo string      `json:"protocolo"`
+	Host      string      `json:"host"`
+	Inicio    time.Time   `json:"inicio"`
+	DuracaoMs float64     `json:"duracao_ms"`
+	Sucesso   bool        `json:"sucesso"`
+	Erro      string      `json:"erro,omitempty"`
+	Passos    []jsonPasso `json:"passos"`
+}
+
+func writeJSONReport(w io.Writer, rels []relatorio) error {
+	doc := struct {
+		Sucesso    bool            `json:"sucesso"`
+		Protocolos []jsonRelatorio `json:"protocolos"`
+	}{Sucesso: true}
+	for _, r := range rels {
+		jr := jsonRelatorio{
+			Protocolo: r.Protocolo,
+			Host:      r.Host,
+			Inicio:    r.Inicio,
+			DuracaoMs: milissegundos(r.Duracao),
+			Sucesso:   r.Sucesso(),
+			Erro:      r.Erro,
+			Passos:    make([]jsonPasso, len(r.Passos)),
+		}
+		for i, p := range r.Passos {
+			jr.Passos[i] = jsonPasso{i + 1, p.Operacao, milissegundos(p.Duracao), p.Sucesso, p.Campos, p.Erro}
+		}
+		doc.Sucesso = doc.Sucesso && r.Sucesso()
+		doc.Protocolos = append(doc.Protocolos, jr)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// writeTAPReport escreve um teste TAP (versão 13) por passo; os detalhes vão
+// no bloco YAML de cada teste, com os valores em JSON.
+func writeTAPReport(w io.Writer, rels []relatorio) error {
+	total := 0
+	for _, r := range rels {
+		total += len(r.Passos)
+	}
+	fmt.Fprintf(w, "TAP version 13\n1..%d\n", total)
+	n := 0
+	for _, r := range rels {
+		fmt.Fprintf(w, "# %s (%s)\n", r.Protocolo, r.Host)
+		for _, p := range r.Passos {
+			n++
+			status := "ok"
+			if !p.Sucesso {
+				status = "not ok"
+			}
+			fmt.Fprintf(w, "%s %d - %s %s\n  ---\n  duracao_ms: %.3f\n", status, n, r.Protocolo, p.Operacao, milissegundos(p.Duracao))
+			if len(p.Campos) > 0 {
+				b, err := json.Marshal(p.Campos)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "  campos: %s\n", b)
+			}
+			if p.Erro != "" {
+				b, _ := json.Marshal(p.Erro)
+				fmt.Fprintf(w, "  erro: %s\n", b)
+			}
+			if _, err := fmt.Fprintln(w, "  ..."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Texto   string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Nome      string        `xml:"name,attr"`
+	Classe    string        `xml:"classname,attr"`
+	Tempo     string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitOut     `xml:"system-out,omitempty"`
+}
+
+type junitOut struct {
+	Texto string `xml:",cdata"`
+}
+
+type junitSuite struct {
+	Nome      string      `xml:"name,attr"`
+	Testes    int         `xml:"tests,attr"`
+	Falhas    int         `xml:"failures,attr"`
+	Tempo     string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Hostname  string      `xml:"hostname,attr"`
+	Casos     []junitCase `xml:"testcase"`
+}
+
+// writeJUnitReport escreve uma testsuite por protocolo, com um testcase por
+// passo; os campos da resposta vão em system-out.
+func writeJUnitReport(w io.Writer, rels []relatorio) error {
+	doc := struct {
+		XMLName xml.Name     `xml:"testsuites"`
+		Nome    string       `xml:"name,attr"`
+		Testes  int          `xml:"tests,attr"`
+		Falhas  int          `xml:"failures,attr"`
+		Suites  []junitSuite `xml:"testsuite"`
+	}{Nome: "SD-trab1"}
+	segundos := func(d time.Duration) string { return fmt.Sprintf("%.3f", d.Seconds()) }
+	for _, r := range rels {
+		suite := junitSuite{
+			Nome:      r.Protocolo,
+			Testes:    len(r.Passos),
+			Falhas:    r.falhas(),
+			Tempo:     segundos(r.Duracao),
+			Timestamp: r.Inicio.UTC().Format("2006-01-02T15:04:05"),
+			Hostname:  r.Host,
+		}
+		for i, p := range r.Passos {
+			caso := junitCase{
+				Nome:   fmt.Sprintf("%d %s", i+1, p.Operacao),
+				Classe: r.Protocolo,
+				Tempo:  segundos(p.Duracao),
+			}
+			if len(p.Campos) > 0 {
+				b, err := json.Marshal(p.Campos)
+				if err != nil {
+					return err
+				}
+				caso.SystemOut = &junitOut{string(b)}
+			}
+			if !p.Sucesso {
+				caso.Failure = &junitFailure{Message: p.Erro, Texto: p.Erro}
+			}
+			suite.Casos = append(suite.Casos, caso)
+		}
+		doc.Testes += suite.Testes
+		doc.Falhas += suite.Falhas
+		doc.Suites = append(doc.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// writeSummaryTable escreve uma tabela com a duração de cada passo (linhas)
+// por protocolo (colunas), seguida dos erros dos protocolos que falharam.
+func writeSummaryTable(w io.Writer, rels []relatorio) error {
+	var nomes []string
+	for _, r := range rels {
+		if len(r.Passos) > len(nomes) {
+			nomes = nomes[:0]
+			for _, p := range r.Passos {
+				nomes = append(nomes, p.Operacao)
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\t", i18n.T("passo"))
+	for _, r := range rels {
+		fmt.Fprintf(tw, "%s\t", r.Protocolo)
+	}
+	fmt.Fprintln(tw)
+	for i, nome := range nomes {
+		fmt.Fprintf(tw, "%d %s\t", i+1, nome)
+		for _, r := range rels {
+			switch {
+			case i >= len(r.Passos):
+				fmt.Fprint(tw, "-\t")
+			case r.Passos[i].Sucesso:
+				fmt.Fprintf(tw, "%v\t", r.Passos[i].Duracao.Round(time.Microsecond))
+			default:
+				fmt.Fprintf(tw, "%s %v\t", i18n.T("FALHOU"), r.Passos[i].Duracao.Round(time.Microsecond))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "%s\t", i18n.T("total"))
+	for _, r := range rels {
+		fmt.Fprintf(tw, "%v\t", r.Duracao.Round(time.Microsecond))
+	}
+	fmt.Fprintf(tw, "\n%s\t", i18n.T("resultado"))
+	for _, r := range rels {
+		if r.Sucesso() {
+			fmt.Fprint(tw, "OK\t")
+		} else {
+			fmt.Fprintf(tw, "%s\t", i18n.T("FALHOU"))
+		}
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range rels {
+		if !r.Sucesso() {
+			if _, err := fmt.Fprintf(w, "%s: %s\n", r.Protocolo, r.Erro); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regrava os arquivos de testdata")
+
+// relatoriosExemplo tem um protocolo que passou e outro que falhou no Soma,
+// para exercitar o "not ok" do TAP e o <failure> do JUnit.
+func relatoriosExemplo() []relatorio {
+	inicio := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []relatorio{
+		{
+			Protocolo: "json",
+			Host:      "127.0.0.1",
+			Inicio:    inicio,
+			Duracao:   12500 * time.Microsecond,
+			Passos: []passoResultado{
+				{Operacao: "Connect", Duracao: 1200 * time.Microsecond, Sucesso: true, Campos: map[string]any{"host": "127.0.0.1"}},
+				{Operacao: "Auth", Duracao: 3 * time.Millisecond, Sucesso: true, Campos: map[string]any{"nome": "Maria", "token": "abc"}},
+				{Operacao: "OpSoma", Duracao: 2250 * time.Microsecond, Sucesso: true, Campos: map[string]any{"soma": 6.0}},
+			},
+		},
+		{
+			Protocolo: "proto",
+			Host:      "127.0.0.1",
+			Inicio:    inicio.Add(time.Second),
+			Duracao:   8 * time.Millisecond,
+			Erro:      `falha no OpSoma: erro do servidor: "números" inválidos <3>`,
+			Passos: []passoResultado{
+				{Operacao: "Connect", Duracao: 900 * time.Microsecond, Sucesso: true, Campos: map[string]any{"host": "127.0.0.1"}},
+				{Operacao: "Auth", Duracao: 2 * time.Millisecond, Sucesso: true, Campos: map[string]any{"nome": "Maria", "token": "def"}},
+				{Operacao: "OpSoma", Duracao: 1500 * time.Microsecond, Erro: `erro do servidor: "números" inválidos <3>`},
+			},
+		},
+	}
+}
+
+func TestReportGolden(t *testing.T) {
+	casos := []struct {
+		arquivo string
+		write   func(io.Writer, []relatorio) error
+	}{
+		{"report.json", writeJSONReport},
+		{"report.tap", writeTAPReport},
+		{"report.xml", writeJUnitReport},
+		{"report.txt", writeSummaryTable},
+	}
+	for _, caso := range casos {
+		t.Run(caso.arquivo, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := caso.write(&buf, relatoriosExemplo()); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", caso.arquivo)
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("saída diferente de %s (regrave com -update):\n%s", golden, buf.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	"github.com/GuilhermeGalvao1/SD-trab1/wire"
+)
+
+func runServeCommand(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	proto := fs.String("proto", "all", i18n.T("Protocolo a servir (string, json, proto, msgpack, cbor ou all)"))
+	addr := fs.String("addr", "127.0.0.1", i18n.T("Endereço onde escutar"))
+	compress := fs.String("compress", strings.Join(wire.Compressoes, ","), i18n.T("Algoritmos de compressão aceitos, em ordem de preferência (none desativa)"))
+	certFile := fs.String("tls-cert", "", i18n.T("Certificado (PEM) para servir com TLS; exige -tls-key"))
+	keyFile := fs.String("tls-key", "", i18n.T("Chave privada (PEM) do certificado de -tls-cert"))
+	lang := addLangFlag(fs)
+	fs.Parse(args)
+
+	if err := setLang(*lang); err != nil {
+		log.Fatal(err)
+	}
+
+	algs, err := wire.ParseCompressoes(*compress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var tlsConfig *tls.Config
+	if *certFile != "" || *keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao carregar o certificado TLS: %v"), err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	protos := []string{*proto}
+	if *proto == "all" {
+		protos = []string{"string", "json", "proto", "msgpack", "cbor"}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, p := range protos {
+		port, ok := wire.Ports[p]
+		if !ok {
+			log.Fatalf(i18n.T("Protocolo '%s' desconhecido. Use 'string', 'json', 'proto', 'msgpack', 'cbor' ou 'all'."), p)
+		}
+		srv, err := wire.NewTestServer(p, algs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(*addr, port))
+		if err != nil {
+			log.Fatalf(i18n.T("falha ao escutar em %s:%s: %v"), *addr, port, err)
+		}
+		if tlsConfig != nil {
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+		log.Printf(i18n.T("Servidor de teste %s em %s"), p, ln.Addr())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Serve(ctx, ln); err != nil {
+				log.Printf(i18n.T("servidor %s encerrado: %v"), p, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
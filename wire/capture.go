@@ -0,0 +1,169 @@
+package wire
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DirecaoRequisicao = "req"
+	DirecaoResposta   = "resp"
+)
+
+// Frame é uma mensagem completa capturada no fio. Os arquivos de captura têm
+// um Frame em JSON por linha.
+type Frame struct {
+	Timestamp time.Time `json:"timestamp"`
+	Sessao    int       `json:"sessao"`
+	Protocolo string    `json:"protocolo"`
+	Direcao   string    `json:"direcao"`
+	Dados     []byte    `json:"dados"`
+}
+
+// Recorder grava os frames das conexões embrulhadas por Wrap. A gravação não
+// interrompe a conexão: a primeira falha é guardada, os frames seguintes são
+// descartados e o erro fica disponível em Err e Close.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	enc    *json.Encoder
+	sessao int
+	err    error
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w)}
+}
+
+func (r *Recorder) record(f Frame) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(f); err != nil {
+		r.err = fmt.Errorf("wire: falha ao gravar captura: %w", err)
+	}
+}
+
+// Err devolve a primeira falha de gravação, ou nil.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close fecha o destino da captura, se for um io.Closer, e devolve a primeira
+// falha de gravação ou, sem ela, a do fechamento.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.err
+	if c, ok := r.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Wrap devolve uma conexão que registra cada mensagem trafegada em conn como
+// uma nova sessão da captura.
+func (r *Recorder) Wrap(conn net.Conn, protocolo string) (net.Conn, error) {
+	split, err := SplitFunc(protocolo)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.sessao++
+	sessao := r.sessao
+	r.mu.Unlock()
+
+	rc := &recordingConn{Conn: conn}
+	rc.req = frameBuffer{rec: r, split: split, base: Frame{Sessao: sessao, Protocolo: protocolo, Direcao: DirecaoRequisicao}}
+	rc.resp = frameBuffer{rec: r, split: split, base: Frame{Sessao: sessao, Protocolo: protocolo, Direcao: DirecaoResposta}}
+	return rc, nil
+}
+
+type frameBuffer struct {
+	mu    sync.Mutex
+	rec   *Recorder
+	split bufio.SplitFunc
+	base  Frame
+	buf   []byte
+}
+
+func (b *frameBuffer) write(p []byte, atEOF bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	for len(b.buf) > 0 {
+		advance, token, err := b.split(b.buf, atEOF)
+		if err != nil || advance == 0 {
+			return
+		}
+		if token != nil {
+			f := b.base
+			f.Timestamp = time.Now()
+			f.Dados = append([]byte(nil), token...)
+			b.rec.record(f)
+		}
+		b.buf = b.buf[advance:]
+	}
+}
+
+type recordingConn struct {
+	net.Conn
+	req  frameBuffer
+	resp frameBuffer
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.req.write(p[:n], false)
+	return n, err
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.resp.write(p[:n], err != nil)
+	return n, err
+}
+
+func (c *recordingConn) Close() error {
+	c.req.write(nil, true)
+	c.resp.write(nil, true)
+	return c.Conn.Close()
+}
+
+func ReadFrames(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	dec := json.NewDecoder(r)
+	for {
+		var f Frame
+		if err := dec.Decode(&f); err != nil {
+			if errors.Is(err, io.EOF) {
+				return frames, nil
+			}
+			return nil, fmt.Errorf("wire: captura inválida: %w", err)
+		}
+		frames = append(frames, f)
+	}
+}
+
+func ReadCaptureFile(path string) ([]Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFrames(f)
+}
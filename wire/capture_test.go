@@ -0,0 +1,86 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+)
+
+type falhaWriter struct {
+	n int
+}
+
+var errDiscoCheio = errors.New("disco cheio")
+
+func (w *falhaWriter) Write(p []byte) (int, error) {
+	w.n++
+	return 0, errDiscoCheio
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	a, b := net.Pipe()
+	conn, err := rec.Wrap(a, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		p := make([]byte, 64)
+		n, _ := b.Read(p)
+		b.Write(p[:n])
+		b.Close()
+	}()
+	conn.Write([]byte(`{"tipo":"echo"}` + "\n"))
+	p := make([]byte, 64)
+	conn.Read(p)
+	conn.Close()
+
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	frames, err := ReadFrames(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[0].Direcao != DirecaoRequisicao || frames[1].Direcao != DirecaoResposta {
+		t.Fatalf("frames = %+v", frames)
+	}
+	if string(frames[1].Dados) != `{"tipo":"echo"}` {
+		t.Errorf("dados = %q", frames[1].Dados)
+	}
+}
+
+func TestRecorderKeepsFirstError(t *testing.T) {
+	w := &falhaWriter{}
+	rec := NewRecorder(w)
+	a, b := net.Pipe()
+	defer b.Close()
+	conn, _ := rec.Wrap(a, "json")
+	go func() {
+		p := make([]byte, 64)
+		for {
+			if _, err := b.Read(p); err != nil {
+				return
+			}
+		}
+	}()
+	// A conexão continua funcionando mesmo com a captura falhando.
+	for range 3 {
+		if _, err := conn.Write([]byte("{}\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Close()
+
+	if !errors.Is(rec.Err(), errDiscoCheio) {
+		t.Fatalf("Err() = %v, esperado %v", rec.Err(), errDiscoCheio)
+	}
+	if w.n != 1 {
+		t.Errorf("%d gravações depois da falha, esperado 1", w.n)
+	}
+	if err := rec.Close(); !errors.Is(err, errDiscoCheio) {
+		t.Errorf("Close() = %v, esperado %v", err, errDiscoCheio)
+	}
+}
@@ -0,0 +1,285 @@
+package wire
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algoritmos de compressão aceitos nos transportes JSON e protobuf, na ordem
+// de preferência usada quando nenhuma é informada.
+const (
+	CompressaoZstd   = "zstd"
+	CompressaoGzip   = "gzip"
+	CompressaoSnappy = "snappy"
+)
+
+var Compressoes = []string{CompressaoZstd, CompressaoGzip, CompressaoSnappy}
+
+// Mensagens menores que MinCompressSize são enviadas sem compressão mesmo
+// depois da negociação: o ganho não compensa o cabeçalho dos algoritmos.
+const MinCompressSize = 512
+
+// CampoAceitaCompressao é o parâmetro (protobuf) ou campo (JSON) em que cada
+// lado anuncia os algoritmos que aceita, separados por vírgula.
+const CampoAceitaCompressao = "aceita_codificacao"
+
+var ErrDescompressaoLimite = errors.New("wire: mensagem descomprimida excede o limite")
+
+var compressaoIDs = map[string]byte{
+	CompressaoGzip:   1,
+	CompressaoZstd:   2,
+	CompressaoSnappy: 3,
+}
+
+func compressaoPorID(id byte) (string, error) {
+	for nome, v := range compressaoIDs {
+		if v == id {
+			return nome, nil
+		}
+	}
+	return "", fmt.Errorf("wire: algoritmo de compressão desconhecido (id %d)", id)
+}
+
+// ParseCompressoes valida uma lista separada por vírgulas de algoritmos.
+// "none" ou vazio resultam numa lista vazia (sem compressão).
+func ParseCompressoes(s string) ([]string, error) {
+	var algs []string
+	for _, a := range strings.Split(s, ",") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || a == "none" {
+			continue
+		}
+		if _, ok := compressaoIDs[a]; !ok {
+			return nil, fmt.Errorf("wire: algoritmo de compressão '%s' desconhecido (use %s)", a, strings.Join(Compressoes, ", "))
+		}
+		if !slices.Contains(algs, a) {
+			algs = append(algs, a)
+		}
+	}
+	return algs, nil
+}
+
+// Negotiate escolhe o primeiro algoritmo da oferta (na ordem de preferência de
+// quem a enviou) que também está em suportadas. Vazio indica sem compressão.
+func Negotiate(oferta string, suportadas []string) string {
+	for _, a := range strings.Split(oferta, ",") {
+		a = strings.TrimSpace(a)
+		if slices.Contains(suportadas, a) {
+			return a
+		}
+	}
+	return ""
+}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	zstdDecoders   = sync.Pool{New: func() any {
+		d, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		return d
+	}}
+)
+
+func Compress(alg string, data []byte) ([]byte, error) {
+	switch alg {
+	case CompressaoGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressaoZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	case CompressaoSnappy:
+		return snappy.Encode(nil, data), nil
+	}
+	return nil, fmt.Errorf("wire: algoritmo de compressão '%s' desconhecido", alg)
+}
+
+// Decompress recusa com ErrDescompressaoLimite conteúdos que, descomprimidos,
+// passariam de limite bytes, sem chegar a alocá-los.
+func Decompress(alg string, data []byte, limite int64) ([]byte, error) {
+	var r io.Reader
+	switch alg {
+	case CompressaoGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	case CompressaoZstd:
+		d := zstdDecoders.Get().(*zstd.Decoder)
+		defer zstdDecoders.Put(d)
+		if err := d.Reset(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		r = d
+	case CompressaoSnappy:
+		n, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if int64(n) > limite {
+			return nil, ErrDescompressaoLimite
+		}
+		return snappy.Decode(nil, data)
+	default:
+		return nil, fmt.Errorf("wire: algoritmo de compressão '%s' desconhecido", alg)
+	}
+
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(io.LimitReader(r, limite+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > limite {
+		return nil, ErrDescompressaoLimite
+	}
+	return buf.Bytes(), nil
+}
+
+// Frames protobuf estendidos: o bit mais alto do cabeçalho de tamanho indica
+// que há um byte extra com o algoritmo negociado pelo remetente, e o bit
+// seguinte que o payload está comprimido com ele. Servidores sem suporte
+// nunca recebem esse formato, pois o cliente só o usa depois que o servidor
+// responde com um frame estendido.
+const (
+	flagEstendido  = 1 << 31
+	flagComprimido = 1 << 30
+	mascaraTamanho = flagComprimido - 1
+)
+
+// ProtoHeader é o cabeçalho de um frame protobuf, simples ou estendido.
+type ProtoHeader struct {
+	Tamanho    int64
+	Estendido  bool
+	Comprimido bool
+}
+
+// Len devolve o tamanho do cabeçalho em bytes.
+func (h ProtoHeader) Len() int {
+	if h.Estendido {
+		return 5
+	}
+	return 4
+}
+
+func ParseProtoHeader(hdr []byte) ProtoHeader {
+	v := binary.BigEndian.Uint32(hdr[:4])
+	if v&flagEstendido == 0 {
+		return ProtoHeader{Tamanho: int64(v)}
+	}
+	return ProtoHeader{
+		Tamanho:    int64(v & mascaraTamanho),
+		Estendido:  true,
+		Comprimido: v&flagComprimido != 0,
+	}
+}
+
+// AppendProtoFrame acrescenta a dst o frame de payload. Com alg vazio o frame
+// é simples; senão é estendido e o payload é comprimido com alg se tiver ao
+// menos MinCompressSize bytes.
+func AppendProtoFrame(dst, payload []byte, alg string) ([]byte, error) {
+	if alg == "" {
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+		return append(dst, payload...), nil
+	}
+	id, ok := compressaoIDs[alg]
+	if !ok {
+		return nil, fmt.Errorf("wire: algoritmo de compressão '%s' desconhecido", alg)
+	}
+	v := uint32(flagEstendido)
+	if len(payload) >= MinCompressSize {
+		comprimido, err := Compress(alg, payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = comprimido
+		v |= flagComprimido
+	}
+	if len(payload) > mascaraTamanho {
+		return nil, fmt.Errorf("wire: payload de %d bytes excede o tamanho máximo do frame", len(payload))
+	}
+	dst = binary.BigEndian.AppendUint32(dst, v|uint32(len(payload)))
+	dst = append(dst, id)
+	return append(dst, payload...), nil
+}
+
+// ProtoPayload extrai o payload (já descomprimido) de um frame completo e o
+// algoritmo anunciado no cabeçalho estendido, se houver.
+func ProtoPayload(frame []byte, limite int64) (payload []byte, alg string, err error) {
+	if len(frame) < 4 {
+		return nil, "", fmt.Errorf("wire: frame protobuf truncado (%d bytes)", len(frame))
+	}
+	h := ParseProtoHeader(frame)
+	if len(frame) < h.Len() {
+		return nil, "", fmt.Errorf("wire: frame protobuf truncado (%d bytes)", len(frame))
+	}
+	payload = frame[h.Len():]
+	if !h.Estendido {
+		return payload, "", nil
+	}
+	if alg, err = compressaoPorID(frame[4]); err != nil {
+		return nil, "", err
+	}
+	if h.Comprimido {
+		payload, err = Decompress(alg, payload, limite)
+	}
+	return payload, alg, err
+}
+
+// JSONEnvelope substitui no fio um documento JSON comprimido. Dados traz o
+// documento original comprimido com Codificacao (em base64, como todo []byte
+// no encoding/json).
+type JSONEnvelope struct {
+	Codificacao string `json:"codificacao"`
+	Dados       []byte `json:"dados"`
+}
+
+// WrapJSON devolve doc dentro de um JSONEnvelope comprimido com alg, ou o
+// próprio doc se alg for vazio ou doc tiver menos de MinCompressSize bytes.
+func WrapJSON(doc []byte, alg string) ([]byte, error) {
+	if alg == "" || len(doc) < MinCompressSize {
+		return doc, nil
+	}
+	dados, err := Compress(alg, doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(JSONEnvelope{Codificacao: alg, Dados: dados})
+}
+
+// UnwrapJSON desfaz WrapJSON. Além do documento original devolve o algoritmo
+// que o remetente aceita: o do envelope ou, num documento comum, o anunciado
+// em CampoAceitaCompressao.
+func UnwrapJSON(doc []byte, limite int64) (orig []byte, alg string, err error) {
+	var env struct {
+		JSONEnvelope
+		Aceita string `json:"aceita_codificacao"`
+	}
+	if err := json.Unmarshal(doc, &env); err != nil {
+		// Documentos que não são objetos seguem adiante para o erro
+		// aparecer na decodificação de quem chamou.
+		return doc, "", nil
+	}
+	if env.Codificacao == "" || env.Dados == nil {
+		return doc, env.Aceita, nil
+	}
+	orig, err = Decompress(env.Codificacao, env.Dados, limite)
+	return orig, env.Codificacao, err
+}
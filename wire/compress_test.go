@@ -0,0 +1,241 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// historicoDoc é uma resposta de histórico típica, com 50 operações: o
+// caso em que a compressão mais importa.
+func historicoDoc(tb testing.TB) []byte {
+	tb.Helper()
+	ops := make([]registroOp, 50)
+	inicio := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := range ops {
+		ops[i] = registroOp{
+			Operacao:  []string{"echo", "soma", "timestamp", "status"}[i%4],
+			Timestamp: inicio.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano),
+			Sucesso:   i%7 != 0,
+		}
+	}
+	doc, err := json.Marshal(map[string]any{
+		"sucesso":   true,
+		"resultado": map[string]any{"historico": ops, "total": len(ops)},
+		"timestamp": inicio.Format(time.RFC3339),
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return doc
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	doc := historicoDoc(t)
+	for _, alg := range Compressoes {
+		comprimido, err := Compress(alg, doc)
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if len(comprimido) >= len(doc) {
+			t.Errorf("%s: %d bytes comprimidos, original %d", alg, len(comprimido), len(doc))
+		}
+		orig, err := Decompress(alg, comprimido, int64(len(doc)))
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if !bytes.Equal(orig, doc) {
+			t.Errorf("%s: conteúdo descomprimido difere do original", alg)
+		}
+		if _, err := Decompress(alg, comprimido, int64(len(doc)-1)); !errors.Is(err, ErrDescompressaoLimite) {
+			t.Errorf("%s: limite abaixo do tamanho: %v, esperado ErrDescompressaoLimite", alg, err)
+		}
+	}
+}
+
+func TestProtoFrameRoundTrip(t *testing.T) {
+	pequeno := []byte("mensagem curta")
+	grande := historicoDoc(t)
+	casos := []struct {
+		alg        string
+		payload    []byte
+		estendido  bool
+		comprimido bool
+	}{
+		{"", pequeno, false, false},
+		{"", grande, false, false},
+		{CompressaoGzip, pequeno, true, false},
+		{CompressaoGzip, grande, true, true},
+		{CompressaoZstd, grande, true, true},
+		{CompressaoSnappy, grande, true, true},
+	}
+	for _, c := range casos {
+		t.Run(fmt.Sprintf("%s/%d", c.alg, len(c.payload)), func(t *testing.T) {
+			frame, err := AppendProtoFrame([]byte("prefixo"), c.payload, c.alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(frame, []byte("prefixo")) {
+				t.Fatal("AppendProtoFrame não preservou dst")
+			}
+			frame = frame[len("prefixo"):]
+
+			v := binary.BigEndian.Uint32(frame)
+			if got := v&(1<<31) != 0; got != c.estendido {
+				t.Errorf("bit 31 = %v, esperado %v", got, c.estendido)
+			}
+			if got := v&(1<<30) != 0; got != c.comprimido {
+				t.Errorf("bit 30 = %v, esperado %v", got, c.comprimido)
+			}
+			h := ParseProtoHeader(frame)
+			if h.Estendido != c.estendido || h.Comprimido != c.comprimido {
+				t.Errorf("ParseProtoHeader = %+v", h)
+			}
+			if int(h.Tamanho) != len(frame)-h.Len() {
+				t.Errorf("Tamanho = %d, esperado %d", h.Tamanho, len(frame)-h.Len())
+			}
+			if c.estendido && frame[4] != compressaoIDs[c.alg] {
+				t.Errorf("byte do algoritmo = %d, esperado %d", frame[4], compressaoIDs[c.alg])
+			}
+
+			payload, alg, err := ProtoPayload(frame, 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alg != c.alg || !bytes.Equal(payload, c.payload) {
+				t.Errorf("ProtoPayload = %d bytes, %q; esperado %d bytes, %q", len(payload), alg, len(c.payload), c.alg)
+			}
+		})
+	}
+}
+
+func TestProtoPayloadErrors(t *testing.T) {
+	grande := historicoDoc(t)
+	frame, _ := AppendProtoFrame(nil, grande, CompressaoZstd)
+
+	if _, _, err := ProtoPayload(frame[:3], 1<<20); err == nil {
+		t.Error("frame truncado aceito")
+	}
+	if _, _, err := ProtoPayload(frame[:4], 1<<20); err == nil {
+		t.Error("frame estendido sem o byte do algoritmo aceito")
+	}
+	desconhecido := bytes.Clone(frame)
+	desconhecido[4] = 99
+	if _, _, err := ProtoPayload(desconhecido, 1<<20); err == nil {
+		t.Error("algoritmo desconhecido aceito")
+	}
+	if _, _, err := ProtoPayload(frame, int64(len(grande)-1)); !errors.Is(err, ErrDescompressaoLimite) {
+		t.Errorf("limite de descompressão: %v", err)
+	}
+	if _, err := AppendProtoFrame(nil, grande, "lz4"); err == nil {
+		t.Error("AppendProtoFrame aceitou algoritmo desconhecido")
+	}
+}
+
+func TestJSONEnvelopeRoundTrip(t *testing.T) {
+	grande := historicoDoc(t)
+	for _, alg := range Compressoes {
+		doc, err := WrapJSON(grande, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var env JSONEnvelope
+		if err := json.Unmarshal(doc, &env); err != nil {
+			t.Fatalf("%s: envelope inválido: %v", alg, err)
+		}
+		if env.Codificacao != alg || len(env.Dados) == 0 {
+			t.Errorf("%s: envelope = {%s, %d bytes}", alg, env.Codificacao, len(env.Dados))
+		}
+		if len(doc) >= len(grande) {
+			t.Errorf("%s: envelope com %d bytes, original %d", alg, len(doc), len(grande))
+		}
+
+		orig, got, err := UnwrapJSON(doc, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != alg || !bytes.Equal(orig, grande) {
+			t.Errorf("%s: UnwrapJSON devolveu %q e %d bytes", alg, got, len(orig))
+		}
+		if _, _, err := UnwrapJSON(doc, int64(len(grande)-1)); !errors.Is(err, ErrDescompressaoLimite) {
+			t.Errorf("%s: limite de descompressão: %v", alg, err)
+		}
+	}
+}
+
+func TestJSONEnvelopeUncompressed(t *testing.T) {
+	pequeno := []byte(`{"tipo":"operacao","aceita_codificacao":"zstd,gzip"}`)
+	doc, err := WrapJSON(pequeno, CompressaoZstd)
+	if err != nil || !bytes.Equal(doc, pequeno) {
+		t.Fatalf("WrapJSON de documento pequeno = %s, %v", doc, err)
+	}
+	orig, alg, err := UnwrapJSON(doc, 1<<20)
+	if err != nil || !bytes.Equal(orig, pequeno) || alg != "zstd,gzip" {
+		t.Errorf("UnwrapJSON = %s, %q, %v", orig, alg, err)
+	}
+
+	naoObjeto := []byte(`[1,2]`)
+	if orig, alg, err := UnwrapJSON(naoObjeto, 1<<20); err != nil || !bytes.Equal(orig, naoObjeto) || alg != "" {
+		t.Errorf("UnwrapJSON de array = %s, %q, %v", orig, alg, err)
+	}
+}
+
+// BenchmarkCompress compara os algoritmos numa resposta de histórico. Além do
+// tempo, informa o tamanho comprimido (bytes/op) e a razão sobre o original.
+func BenchmarkCompress(b *testing.B) {
+	doc := historicoDoc(b)
+	for _, alg := range Compressoes {
+		b.Run(alg, func(b *testing.B) {
+			var comprimido []byte
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				comprimido, _ = Compress(alg, doc)
+			}
+			b.ReportMetric(float64(len(comprimido)), "bytes/msg")
+			b.ReportMetric(float64(len(comprimido))/float64(len(doc)), "razao")
+		})
+	}
+}
+
+func BenchmarkDecompress(b *testing.B) {
+	doc := historicoDoc(b)
+	for _, alg := range Compressoes {
+		comprimido, _ := Compress(alg, doc)
+		b.Run(alg, func(b *testing.B) {
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				Decompress(alg, comprimido, int64(len(doc)))
+			}
+		})
+	}
+}
+
+// BenchmarkFrameSize compara o tamanho no fio de uma mesma resposta em frames
+// protobuf e documentos JSON, sem compressão e com cada algoritmo.
+func BenchmarkFrameSize(b *testing.B) {
+	doc := historicoDoc(b)
+	for _, alg := range append([]string{""}, Compressoes...) {
+		nome := alg
+		if nome == "" {
+			nome = "none"
+		}
+		b.Run("proto/"+nome, func(b *testing.B) {
+			var frame []byte
+			for b.Loop() {
+				frame, _ = AppendProtoFrame(frame[:0], doc, alg)
+			}
+			b.ReportMetric(float64(len(frame)), "bytes/msg")
+		})
+		b.Run("json/"+nome, func(b *testing.B) {
+			var env []byte
+			for b.Loop() {
+				env, _ = WrapJSON(doc, alg)
+			}
+			b.ReportMetric(float64(len(env)), "bytes/msg")
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ParseHexDump converte um dump hexadecimal em bytes. Aceita hex puro (com ou
+// sem espaços), a saída do xxd e a do hexdump -C.
+func ParseHexDump(r io.Reader) ([]byte, error) {
+	var linhas [][]string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '|'); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, ": "); i >= 0 {
+			line = line[i+2:]
+			if j := strings.Index(line, "  "); j >= 0 {
+				line = line[:j]
+			}
+		}
+		linhas = append(linhas, strings.Fields(line))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	offsets := hasOffsets(linhas)
+	var out, anterior []byte
+	repete := false
+	for n, fields := range linhas {
+		if offsets && len(fields) > 0 {
+			if fields[0] == "*" {
+				repete = true
+				continue
+			}
+			// O "*" do hexdump -C omite linhas iguais à anterior até o
+			// próximo offset.
+			off, _ := strconv.ParseInt(fields[0], 16, 64)
+			for repete && len(anterior) > 0 && int64(len(out)) < off {
+				out = append(out, anterior...)
+			}
+			repete = false
+			fields = fields[1:]
+		}
+		var linha []byte
+		for _, f := range fields {
+			b, err := hex.DecodeString(f)
+			if err != nil {
+				return nil, fmt.Errorf("wire: dump hexadecimal inválido na linha %d: %w", n+1, err)
+			}
+			linha = append(linha, b...)
+		}
+		if len(linha) > 0 {
+			out = append(out, linha...)
+			anterior = linha
+		}
+	}
+	return out, nil
+}
+
+// hasOffsets indica se a primeira coluna é o offset do hexdump -C: um número
+// hexadecimal com 7 ou mais dígitos que começa em zero e cresce exatamente com
+// os bytes de cada linha. Sem isso (como em "deadbeef 01 02"), tudo é dado.
+func hasOffsets(linhas [][]string) bool {
+	var total, ultima int64
+	dados, repete := false, false
+	for _, fields := range linhas {
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 1 && fields[0] == "*" {
+			repete = true
+			continue
+		}
+		if len(fields[0]) < 7 || !isHex(fields[0]) {
+			return false
+		}
+		off, err := strconv.ParseInt(fields[0], 16, 64)
+		if err != nil {
+			return false
+		}
+		if off != total {
+			if !repete || ultima == 0 || off < total || (off-total)%ultima != 0 {
+				return false
+			}
+			total = off
+		}
+		repete = false
+		var n int64
+		for _, f := range fields[1:] {
+			n += int64(len(f) / 2)
+		}
+		if n > 0 {
+			dados = true
+			ultima = n
+		}
+		total += n
+	}
+	return dados
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitStream separa um fluxo bruto do protocolo em mensagens.
+func SplitStream(protocolo string, data []byte) ([][]byte, error) {
+	split, err := SplitFunc(protocolo)
+	if err != nil {
+		return nil, err
+	}
+	var frames [][]byte
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	sc.Split(split)
+	for sc.Scan() {
+		frames = append(frames, slices.Clone(sc.Bytes()))
+	}
+	return frames, sc.Err()
+}
+
+// Limite para o conteúdo descomprimido das mensagens mostradas por DecodeFrame.
+const limiteDecode = 64 << 20
+
+// DecodeFrame escreve em w uma versão legível de uma mensagem do protocolo.
+// direcao ("req", "resp" ou vazio) só é usada no protobuf, para escolher entre
+// Requisicao e Resposta; vazio tenta detectar pelo conteúdo.
+func DecodeFrame(w io.Writer, protocolo, direcao string, frame []byte) error {
+	switch protocolo {
+	case "string":
+		return decodeString(w, frame)
+	case "json":
+		return decodeJSON(w, frame)
+	case "proto":
+		return decodeProto(w, direcao, frame)
+	case "msgpack", "cbor":
+		return decodeEnvelope(w, protocolo, frame)
+	}
+	return fmt.Errorf("wire: protocolo '%s' desconhecido", protocolo)
+}
+
+func decodeString(w io.Writer, frame []byte) error {
+	line := strings.TrimRight(string(frame), "\r\n")
+	parts := strings.Split(line, "|")
+	fim := parts[len(parts)-1] == "FIM"
+	if fim {
+		parts = parts[:len(parts)-1]
+	}
+
+	fmt.Fprintf(w, "%s\n", parts[0])
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			fmt.Fprintf(w, "  %s = %s\n", k, v)
+		} else {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+	if !fim {
+		fmt.Fprintln(w, "  "+i18n.T("(mensagem sem terminador FIM)"))
+	}
+	return nil
+}
+
+func decodeJSON(w io.Writer, frame []byte) error {
+	var env JSONEnvelope
+	if json.Unmarshal(frame, &env) == nil && env.Codificacao != "" && env.Dados != nil {
+		doc, err := Decompress(env.Codificacao, env.Dados, limiteDecode)
+		if err != nil {
+			return fmt.Errorf("wire: falha ao descomprimir documento (%s): %w", env.Codificacao, err)
+		}
+		fmt.Fprintln(w, i18n.T("[comprimido com %s: %d bytes -> %d bytes]", env.Codificacao, len(env.Dados), len(doc)))
+		frame = doc
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(frame), "", "  "); err != nil {
+		fmt.Fprintf(w, "%s\n", frame)
+		return fmt.Errorf("wire: documento JSON inválido: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func decodeEnvelope(w io.Writer, protocolo string, frame []byte) error {
+	payload, alg, err := ProtoPayload(frame, limiteDecode)
+	if err != nil {
+		return err
+	}
+	h := ParseProtoHeader(frame)
+	fmt.Fprintln(w, i18n.T("[cabeçalho: %d bytes | payload: %d bytes]", h.Tamanho, len(frame)-h.Len()))
+	switch {
+	case h.Comprimido:
+		fmt.Fprintln(w, i18n.T("[comprimido com %s: %d bytes descomprimidos]", alg, len(payload)))
+	case h.Estendido:
+		fmt.Fprintln(w, i18n.T("[compressão negociada: %s]", alg))
+	}
+
+	var doc any
+	if err := EnvelopeCodecs[protocolo].Unmarshal(payload, &doc); err != nil {
+		return fmt.Errorf("wire: documento %s inválido: %w", protocolo, err)
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
+
+func decodeProto(w io.Writer, direcao string, frame []byte) error {
+	if len(frame) < 4 {
+		return fmt.Errorf("wire: frame protobuf truncado (%d bytes)", len(frame))
+	}
+	h := ParseProtoHeader(frame)
+	if len(frame) < h.Len() {
+		return fmt.Errorf("wire: frame protobuf truncado (%d bytes)", len(frame))
+	}
+	payload := frame[h.Len():]
+	fmt.Fprintln(w, i18n.T("[cabeçalho: %d bytes | payload: %d bytes]", h.Tamanho, len(payload)))
+	if h.Tamanho != int64(len(payload)) {
+		fmt.Fprintln(w, "  "+i18n.T("(payload truncado)"))
+	}
+	if h.Estendido {
+		p, alg, err := ProtoPayload(frame, limiteDecode)
+		if err != nil {
+			return fmt.Errorf("wire: falha ao descomprimir payload: %w", err)
+		}
+		if h.Comprimido {
+			fmt.Fprintln(w, i18n.T("[comprimido com %s: %d bytes descomprimidos]", alg, len(p)))
+		} else {
+			fmt.Fprintln(w, i18n.T("[compressão negociada: %s]", alg))
+		}
+		payload = p
+	}
+
+	var msg proto.Message
+	switch direcao {
+	case DirecaoRequisicao:
+		msg = &pb.Requisicao{}
+	case DirecaoResposta:
+		msg = &pb.Resposta{}
+	default:
+		msg = guessProtoMessage(payload)
+	}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return fmt.Errorf("wire: falha ao decodificar %s: %w", msg.ProtoReflect().Descriptor().Name(), err)
+	}
+	writeMessage(w, msg.ProtoReflect(), 0)
+	return nil
+}
+
+func guessProtoMessage(payload []byte) proto.Message {
+	req := &pb.Requisicao{}
+	if proto.Unmarshal(payload, req) == nil && req.GetConteudo() != nil && !hasUnknown(req.ProtoReflect()) {
+		return req
+	}
+	return &pb.Resposta{}
+}
+
+func hasUnknown(m protoreflect.Message) bool {
+	if len(m.GetUnknown()) > 0 {
+		return true
+	}
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsMap() && !fd.IsList() {
+			found = hasUnknown(v.Message())
+		}
+		return !found
+	})
+	return found
+}
+
+func writeMessage(w io.Writer, m protoreflect.Message, nivel int) {
+	indent := strings.Repeat("  ", nivel)
+	fmt.Fprintf(w, "%s%s {\n", indent, m.Descriptor().Name())
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		switch {
+		case fd.IsMap():
+			fmt.Fprintf(w, "%s  %s:\n", indent, fd.Name())
+			var keys []string
+			entries := make(map[string]string)
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				keys = append(keys, k.String())
+				entries[k.String()] = v.String()
+				return true
+			})
+			slices.Sort(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s    %s = %s\n", indent, k, entries[k])
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			fmt.Fprintf(w, "%s  %s:\n", indent, fd.Name())
+			writeMessage(w, v.Message(), nivel+2)
+		default:
+			fmt.Fprintf(w, "%s  %s: %v\n", indent, fd.Name(), v.Interface())
+		}
+	}
+	if u := m.GetUnknown(); len(u) > 0 {
+		fmt.Fprintf(w, "%s  %s\n", indent, i18n.T("(%d bytes de campos desconhecidos)", len(u)))
+	}
+	fmt.Fprintf(w, "%s}\n", indent)
+}
@@ -0,0 +1,56 @@
+package wire
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseHexDump(t *testing.T) {
+	casos := []struct {
+		nome string
+		dump string
+		want []byte
+	}{
+		{"hex puro", "deadbeef0102", []byte{0xde, 0xad, 0xbe, 0xef, 1, 2}},
+		{"hex puro com espaços", "deadbeef 01 02", []byte{0xde, 0xad, 0xbe, 0xef, 1, 2}},
+		{"hex puro em várias linhas", "0000000a 0b\n0c0d0e0f", []byte{0, 0, 0, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}},
+		{
+			"xxd",
+			"00000000: 4f6c c3a1 0a                             Ol..\n",
+			[]byte("Olá\n"),
+		},
+		{
+			"hexdump -C",
+			"00000000  7b 22 74 69 70 6f 22 3a  22 65 63 68 6f 22 7d 0a  |{\"tipo\":\"echo\"}.|\n" +
+				"00000010  7b 7d                                             |{}|\n" +
+				"00000012\n",
+			[]byte("{\"tipo\":\"echo\"}\n{}"),
+		},
+		{
+			"hexdump -C com linhas repetidas",
+			"00000000  61 61 61 61 61 61 61 61  61 61 61 61 61 61 61 61  |aaaaaaaaaaaaaaaa|\n" +
+				"*\n" +
+				"00000030  62                                                |b|\n" +
+				"00000031\n",
+			append(bytes.Repeat([]byte("a"), 48), 'b'),
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			got, err := ParseHexDump(strings.NewReader(c.dump))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, c.want) {
+				t.Errorf("ParseHexDump = %x, esperado %x", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseHexDumpInvalido(t *testing.T) {
+	if _, err := ParseHexDump(strings.NewReader("01 02\nzz")); err == nil || !strings.Contains(err.Error(), "linha 2") {
+		t.Errorf("erro = %v, esperado falha na linha 2", err)
+	}
+}
@@ -0,0 +1,48 @@
+package wire
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// EnvelopeCodec serializa os envelopes do protocolo JSON (tipo, token,
+// operacao, parametros, resultado...) num formato binário. Os nomes dos
+// campos vêm das tags json das structs, e objetos genéricos são decodificados
+// como map[string]any.
+type EnvelopeCodec struct {
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+}
+
+// EnvelopeCodecs lista os protocolos que transportam os envelopes JSON em
+// frames com prefixo de tamanho, como o protobuf.
+var EnvelopeCodecs = map[string]EnvelopeCodec{
+	"msgpack": {Marshal: msgpackMarshal, Unmarshal: msgpackUnmarshal},
+	"cbor":    {Marshal: cborEnc.Marshal, Unmarshal: cborDec.Unmarshal},
+}
+
+func msgpackMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func msgpackUnmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+var (
+	cborEnc, _ = cbor.EncOptions{ShortestFloat: cbor.ShortestFloat16}.EncMode()
+	cborDec, _ = cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any(nil))}.DecMode()
+)
@@ -0,0 +1,290 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Faults configura as falhas injetadas pelo Proxy nas respostas do servidor.
+// As probabilidades vão de 0 a 1 e são sorteadas a cada resposta.
+type Faults struct {
+	Atraso           time.Duration
+	Descartar        float64
+	Truncar          float64
+	CorromperTamanho float64
+	Reescrever       map[string]string
+}
+
+// Proxy repassa as conexões recebidas para Destino, registrando as mensagens
+// decodificadas e aplicando as falhas configuradas nas respostas.
+type Proxy struct {
+	Protocolo string
+	Destino   string
+	Faults    Faults
+	Logger    *log.Logger
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func NewProxy(protocolo, destino string, faults Faults, seed uint64) (*Proxy, error) {
+	if _, err := SplitFunc(protocolo); err != nil {
+		return nil, err
+	}
+	return &Proxy{
+		Protocolo: protocolo,
+		Destino:   destino,
+		Faults:    faults,
+		Logger:    log.Default(),
+		rand:      rand.New(rand.NewPCG(seed, seed)),
+	}, nil
+}
+
+func (p *Proxy) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for n := 1; ; n++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go p.handle(ctx, conn, n)
+	}
+}
+
+func (p *Proxy) sorteia(prob float64) bool {
+	if prob <= 0 {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rand.Float64() < prob
+}
+
+func (p *Proxy) logf(conexao int, format string, args ...any) {
+	p.Logger.Printf("[proxy %s #%d] %s", p.Protocolo, conexao, i18n.T(format, args...))
+}
+
+func (p *Proxy) logFrame(conexao int, direcao string, frame []byte) {
+	var buf bytes.Buffer
+	if err := DecodeFrame(&buf, p.Protocolo, direcao, frame); err != nil {
+		fmt.Fprintln(&buf, i18n.T("erro: %v", err))
+	}
+	seta := i18n.T("cliente -> servidor")
+	if direcao == DirecaoResposta {
+		seta = i18n.T("servidor -> cliente")
+	}
+	p.logf(conexao, "%s (%d bytes)\n%s", seta, len(frame), strings.TrimRight(buf.String(), "\n"))
+}
+
+func (p *Proxy) handle(ctx context.Context, cliente net.Conn, conexao int) {
+	defer cliente.Close()
+
+	var d net.Dialer
+	servidor, err := d.DialContext(ctx, "tcp", p.Destino)
+	if err != nil {
+		p.logf(conexao, "falha ao conectar ao servidor %s: %v", p.Destino, err)
+		return
+	}
+	defer servidor.Close()
+	p.logf(conexao, "conexão %s -> %s", cliente.RemoteAddr(), p.Destino)
+
+	split, _ := SplitFunc(p.Protocolo)
+	done := make(chan struct{}, 2)
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		sc := newFrameScanner(cliente, split)
+		for sc.Scan() {
+			frame := sc.Bytes()
+			p.logFrame(conexao, DirecaoRequisicao, frame)
+			if _, err := servidor.Write(frame); err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		sc := newFrameScanner(servidor, split)
+		for sc.Scan() {
+			frame := bytes.Clone(sc.Bytes())
+			p.logFrame(conexao, DirecaoResposta, frame)
+			if !p.forward(conexao, cliente, frame) {
+				return
+			}
+		}
+	}()
+
+	<-done
+}
+
+func newFrameScanner(conn net.Conn, split bufio.SplitFunc) *bufio.Scanner {
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	sc.Split(split)
+	return sc
+}
+
+// forward aplica as falhas configuradas e envia a resposta ao cliente. Retorna
+// false quando a conexão deve ser encerrada.
+func (p *Proxy) forward(conexao int, cliente net.Conn, frame []byte) bool {
+	f := p.Faults
+	if f.Atraso > 0 {
+		p.logf(conexao, "falha: atrasando resposta em %v", f.Atraso)
+		time.Sleep(f.Atraso)
+	}
+	if p.sorteia(f.Descartar) {
+		p.logf(conexao, "falha: resposta descartada")
+		return true
+	}
+	if len(f.Reescrever) > 0 {
+		reescrito, err := rewriteFrame(p.Protocolo, frame, f.Reescrever)
+		if err != nil {
+			p.logf(conexao, "falha ao reescrever resposta: %v", err)
+		} else {
+			frame = reescrito
+			p.logFrame(conexao, DirecaoResposta, frame)
+		}
+	}
+	if LengthPrefixed(p.Protocolo) && len(frame) >= 4 && p.sorteia(f.CorromperTamanho) {
+		p.mu.Lock()
+		tamanho := p.rand.Uint32()
+		p.mu.Unlock()
+		binary.BigEndian.PutUint32(frame[:4], tamanho)
+		p.logf(conexao, "falha: cabeçalho de tamanho corrompido para %d", tamanho)
+	}
+	if p.sorteia(f.Truncar) {
+		metade := frame[:len(frame)/2]
+		p.logf(conexao, "falha: resposta truncada em %d de %d bytes; encerrando conexão", len(metade), len(frame))
+		cliente.Write(metade)
+		return false
+	}
+	if _, err := cliente.Write(frame); err != nil {
+		return false
+	}
+	return true
+}
+
+func rewriteFrame(protocolo string, frame []byte, campos map[string]string) ([]byte, error) {
+	switch protocolo {
+	case "string":
+		fim := strings.HasSuffix(string(frame), "\n")
+		parts := strings.Split(strings.TrimRight(string(frame), "\r\n"), "|")
+		for i, part := range parts {
+			if k, _, ok := strings.Cut(part, "="); ok {
+				if v, ok := campos[k]; ok {
+					parts[i] = k + "=" + v
+				}
+			}
+		}
+		out := strings.Join(parts, "|")
+		if fim {
+			out += "\n"
+		}
+		return []byte(out), nil
+
+	case "json":
+		orig, _, err := UnwrapJSON(frame, limiteDecode)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := json.Unmarshal(orig, &doc); err != nil {
+			return nil, err
+		}
+		rewriteJSON(doc, campos)
+		out, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		// Documentos comprimidos voltam comprimidos com o mesmo algoritmo.
+		var env JSONEnvelope
+		if json.Unmarshal(frame, &env) == nil && env.Codificacao != "" {
+			return WrapJSON(out, env.Codificacao)
+		}
+		return out, nil
+
+	case "proto":
+		payload, alg, err := ProtoPayload(frame, limiteDecode)
+		if err != nil {
+			return nil, err
+		}
+		var resp pb.Resposta
+		if err := proto.Unmarshal(payload, &resp); err != nil {
+			return nil, err
+		}
+		r := resp.GetOperacao().GetResultado()
+		for k, v := range campos {
+			if _, ok := r[k]; ok {
+				r[k] = v
+			}
+		}
+		if payload, err = proto.Marshal(&resp); err != nil {
+			return nil, err
+		}
+		return AppendProtoFrame(nil, payload, alg)
+
+	case "msgpack", "cbor":
+		codec := EnvelopeCodecs[protocolo]
+		payload, alg, err := ProtoPayload(frame, limiteDecode)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := codec.Unmarshal(payload, &doc); err != nil {
+			return nil, err
+		}
+		rewriteJSON(doc, campos)
+		if payload, err = codec.Marshal(doc); err != nil {
+			return nil, err
+		}
+		return AppendProtoFrame(nil, payload, alg)
+	}
+	return nil, fmt.Errorf("protocolo '%s' desconhecido", protocolo)
+}
+
+// rewriteJSON substitui, em qualquer nível do documento, os campos existentes
+// com o nome informado. Valores que são JSON válido (números, booleanos...)
+// mantêm o tipo; os demais viram strings.
+func rewriteJSON(doc any, campos map[string]string) {
+	switch d := doc.(type) {
+	case map[string]any:
+		for k, v := range d {
+			if novo, ok := campos[k]; ok {
+				var val any
+				if json.Unmarshal([]byte(novo), &val) != nil {
+					val = novo
+				}
+				d[k] = val
+				continue
+			}
+			rewriteJSON(v, campos)
+		}
+	case []any:
+		for _, v := range d {
+			rewriteJSON(v, campos)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/GuilhermeGalvao1/SD-trab1/i18n"
+)
+
+// ReplayServer responde às requisições de um cliente com as respostas gravadas
+// numa captura, na mesma ordem. A k-ésima conexão recebe as respostas da
+// k-ésima sessão gravada (voltando à primeira quando acabam), e o conteúdo da
+// requisição é ignorado, o que torna a execução determinística mesmo com
+// timestamps diferentes.
+type ReplayServer struct {
+	Protocolo string
+	Logger    *log.Logger
+
+	sessoes [][]Frame
+
+	mu      sync.Mutex
+	proxima int
+}
+
+func NewReplayServer(frames []Frame, protocolo string) (*ReplayServer, error) {
+	if _, err := SplitFunc(protocolo); err != nil {
+		return nil, err
+	}
+	porSessao := make(map[int][]Frame)
+	var ids []int
+	for _, f := range frames {
+		if f.Protocolo != protocolo || f.Direcao != DirecaoResposta {
+			continue
+		}
+		if _, ok := porSessao[f.Sessao]; !ok {
+			ids = append(ids, f.Sessao)
+		}
+		porSessao[f.Sessao] = append(porSessao[f.Sessao], f)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("wire: captura sem respostas do protocolo '%s'", protocolo)
+	}
+	slices.Sort(ids)
+
+	s := &ReplayServer{Protocolo: protocolo, Logger: log.Default()}
+	for _, id := range ids {
+		s.sessoes = append(s.sessoes, porSessao[id])
+	}
+	return s, nil
+}
+
+func (s *ReplayServer) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		s.mu.Lock()
+		sessao := s.sessoes[s.proxima%len(s.sessoes)]
+		s.proxima++
+		s.mu.Unlock()
+		go s.handle(conn, sessao)
+	}
+}
+
+func (s *ReplayServer) handle(conn net.Conn, respostas []Frame) {
+	defer conn.Close()
+	split, _ := SplitFunc(s.Protocolo)
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	sc.Split(split)
+
+	for i := 0; sc.Scan(); i++ {
+		if i >= len(respostas) {
+			s.Logger.Printf("[replay %s] %s", s.Protocolo, i18n.T("requisição %d sem resposta gravada; encerrando conexão", i+1))
+			return
+		}
+		dados := respostas[i].Dados
+		if s.Protocolo == "json" {
+			dados = append(slices.Clone(dados), '\n')
+		}
+		if _, err := conn.Write(dados); err != nil {
+			s.Logger.Printf("[replay %s] %s", s.Protocolo, i18n.T("falha ao responder: %v", err))
+			return
+		}
+	}
+}
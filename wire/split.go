@@ -0,0 +1,100 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+)
+
+var Ports = map[string]string{
+	"string":  "8080",
+	"json":    "8081",
+	"proto":   "8082",
+	"msgpack": "8083",
+	"cbor":    "8084",
+}
+
+// SplitFunc devolve a função que separa o fluxo TCP do protocolo em mensagens
+// completas. Os tokens preservam os bytes do fio (incluindo o '\n' do String e
+// o cabeçalho do protobuf), exceto espaços entre documentos JSON.
+func SplitFunc(protocolo string) (bufio.SplitFunc, error) {
+	switch protocolo {
+	case "string":
+		return splitLines, nil
+	case "json":
+		return splitJSON, nil
+	case "proto", "msgpack", "cbor":
+		return splitLengthPrefixed, nil
+	}
+	return nil, fmt.Errorf("wire: protocolo '%s' desconhecido", protocolo)
+}
+
+func splitLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func splitJSON(data []byte, atEOF bool) (int, []byte, error) {
+	start := 0
+	for start < len(data) && isSpace(data[start]) {
+		start++
+	}
+	if start == len(data) {
+		return start, nil, nil
+	}
+
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && b == '\\':
+			escaped = true
+		case b == '"':
+			inString = !inString
+		case inString:
+		case b == '{' || b == '[':
+			depth++
+		case b == '}' || b == ']':
+			depth--
+			if depth == 0 {
+				return i + 1, data[start : i+1], nil
+			}
+		}
+	}
+	if atEOF {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\r' || b == '\t'
+}
+
+// LengthPrefixed informa se as mensagens do protocolo usam o cabeçalho de
+// tamanho do protobuf.
+func LengthPrefixed(protocolo string) bool {
+	return protocolo == "proto" || protocolo == "msgpack" || protocolo == "cbor"
+}
+
+func splitLengthPrefixed(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) >= 4 {
+		h := ParseProtoHeader(data)
+		size := h.Len() + int(h.Tamanho)
+		if len(data) >= size {
+			return size, data[:size], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,504 @@
+package wire
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/GuilhermeGalvao1/SD-trab1/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TestServer é um servidor local com as operações dos três protocolos, no
+// formato de resposta do servidor da disciplina, para testar o cliente sem
+// acesso a ele. Também atende msgpack e cbor. Fora do String, aceita negociar
+// os algoritmos de Compressoes (vazio desativa a compressão).
+type TestServer struct {
+	Protocolo   string
+	Compressoes []string
+	Logger      *log.Logger
+
+	mu        sync.Mutex
+	alunos    map[string]string
+	historico map[string][]registroOp
+	operacoes int
+}
+
+type registroOp struct {
+	Operacao  string `json:"operacao"`
+	Timestamp string `json:"timestamp"`
+	Sucesso   bool   `json:"sucesso"`
+}
+
+// campo preserva a ordem dos campos, da qual o protocolo String depende.
+// Valores []campo são objetos aninhados.
+type campo struct {
+	chave string
+	valor any
+}
+
+const limiteTestServer = 16 << 20
+
+func NewTestServer(protocolo string, compressoes []string) (*TestServer, error) {
+	if _, err := SplitFunc(protocolo); err != nil {
+		return nil, err
+	}
+	return &TestServer{
+		Protocolo:   protocolo,
+		Compressoes: compressoes,
+		Logger:      log.Default(),
+		alunos:      make(map[string]string),
+		historico:   make(map[string][]registroOp),
+	}, nil
+}
+
+func (s *TestServer) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *TestServer) handle(conn net.Conn) {
+	defer conn.Close()
+	split, _ := SplitFunc(s.Protocolo)
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), limiteTestServer)
+	sc.Split(split)
+
+	// Algoritmo negociado nesta conexão.
+	var alg string
+	for sc.Scan() {
+		var resp []byte
+		var err error
+		switch s.Protocolo {
+		case "string":
+			resp = s.respondString(sc.Bytes())
+		case "json":
+			resp, err = s.respondJSON(sc.Bytes(), &alg)
+		case "proto":
+			resp, err = s.respondProto(sc.Bytes(), &alg)
+		default:
+			resp, err = s.respondEnvelope(sc.Bytes(), &alg)
+		}
+		if err != nil {
+			s.Logger.Printf("[servidor %s] requisição inválida: %v; encerrando conexão", s.Protocolo, err)
+			return
+		}
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *TestServer) auth(alunoID string) (string, []campo, error) {
+	if strings.TrimSpace(alunoID) == "" {
+		return "", nil, fmt.Errorf("aluno_id obrigatório")
+	}
+	var b [16]byte
+	rand.Read(b[:])
+	token := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	s.alunos[token] = alunoID
+	s.mu.Unlock()
+	return token, []campo{
+		{"token", token},
+		{"nome", "Aluno " + alunoID},
+		{"matricula", alunoID},
+	}, nil
+}
+
+// execute realiza a operação op (echo, soma, ..., info, logout) com os
+// parâmetros já convertidos para texto e a registra no histórico do aluno,
+// que continua disponível nas sessões seguintes.
+func (s *TestServer) execute(token, op string, params map[string]string) ([]campo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aluno, ok := s.alunos[token]
+	if !ok {
+		return nil, fmt.Errorf("token inválido ou expirado")
+	}
+	agora := time.Now().UTC()
+	res, err := s.executeLocked(aluno, op, params, agora)
+	s.operacoes++
+	s.historico[aluno] = append(s.historico[aluno], registroOp{
+		Operacao:  op,
+		Timestamp: agora.Format("2006-01-02T15:04:05.999999"),
+		Sucesso:   err == nil,
+	})
+	if op == "logout" && err == nil {
+		delete(s.alunos, token)
+	}
+	return res, err
+}
+
+func (s *TestServer) executeLocked(aluno, op string, params map[string]string, agora time.Time) ([]campo, error) {
+	switch op {
+	case "echo":
+		msg := params["mensagem"]
+		soma := md5.Sum([]byte(msg))
+		return []campo{
+			{"mensagem_original", msg},
+			{"mensagem_eco", msg},
+			{"timestamp_servidor", agora.Format("2006-01-02T15:04:05.999999")},
+			{"tamanho_mensagem", len(msg)},
+			{"hash_md5", hex.EncodeToString(soma[:])},
+		}, nil
+
+	case "soma":
+		var nums []float64
+		for _, n := range strings.Split(params["numeros"], ",") {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+				nums = append(nums, f)
+			}
+		}
+		if len(nums) == 0 {
+			return nil, fmt.Errorf("nenhum número válido informado")
+		}
+		var soma float64
+		for _, n := range nums {
+			soma += n
+		}
+		return []campo{
+			{"numeros", params["numeros"]},
+			{"quantidade", len(nums)},
+			{"soma", soma},
+			{"media", soma / float64(len(nums))},
+			{"maximo", slices.Max(nums)},
+			{"minimo", slices.Min(nums)},
+		}, nil
+
+	case "timestamp":
+		return []campo{
+			{"timestamp_formatado", agora.Format("02/01/2006 15:04:05")},
+			{"timezone", "UTC"},
+			{"timestamp_iso", agora.Format("2006-01-02T15:04:05.999999")},
+		}, nil
+
+	case "status":
+		res := []campo{
+			{"status", "ATIVO"},
+			{"operacoes_processadas", s.operacoes},
+		}
+		if params["detalhado"] == "true" {
+			res = append(res, campo{"estatisticas_banco", []campo{
+				{"total_operacoes", s.operacoes},
+				{"sessoes_ativas", len(s.alunos)},
+				{"versao_protocolo", s.Protocolo},
+			}})
+		}
+		return res, nil
+
+	case "historico":
+		// As páginas vão da operação mais recente para a mais antiga; o
+		// cursor é a posição absoluta do início da página anterior, que não
+		// muda quando novas operações entram no histórico.
+		todas := s.historico[aluno]
+		fim := len(todas)
+		if cursor, err := strconv.Atoi(params["cursor"]); err == nil {
+			fim = max(0, min(cursor, fim))
+		} else if offset, err := strconv.Atoi(params["offset"]); err == nil && offset > 0 {
+			fim = max(0, fim-offset)
+		}
+		inicio := 0
+		if limite, err := strconv.Atoi(params["limite"]); err == nil && limite > 0 {
+			inicio = max(0, fim-limite)
+		}
+		ops := slices.Clone(todas[inicio:fim])
+		sucesso := 0
+		for _, o := range ops {
+			if o.Sucesso {
+				sucesso++
+			}
+		}
+		res := []campo{
+			{"historico", ops},
+			{"estatisticas", []campo{{"total", len(ops)}, {"sucesso", sucesso}, {"falhas", len(ops) - sucesso}}},
+		}
+		if inicio > 0 {
+			res = append(res, campo{"proximo_cursor", strconv.Itoa(inicio)})
+		}
+		return res, nil
+
+	case "info":
+		return []campo{
+			{"nome", "Servidor de teste (" + s.Protocolo + ")"},
+			{"versao", "1.0"},
+			{"capacidades", "echo,soma,timestamp,status,historico"},
+		}, nil
+
+	case "logout":
+		return []campo{
+			{"mensagem", "Logout realizado com sucesso"},
+			{"status", "ok"},
+		}, nil
+	}
+	return nil, fmt.Errorf("operação '%s' desconhecida", op)
+}
+
+func (s *TestServer) respondString(frame []byte) []byte {
+	parts := strings.Split(strings.TrimSpace(string(frame)), "|")
+	kv := make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			kv[k] = v
+		}
+	}
+
+	var res []campo
+	var err error
+	switch parts[0] {
+	case "AUTH":
+		_, res, err = s.auth(kv["aluno_id"])
+	case "OP":
+		kv["numeros"] = kv["nums"]
+		res, err = s.execute(kv["token"], kv["operacao"], kv)
+	case "INFO":
+		res, err = s.execute(kv["token"], "info", kv)
+	case "LOGOUT":
+		res, err = s.execute(kv["token"], "logout", kv)
+	default:
+		err = fmt.Errorf("comando '%s' desconhecido", parts[0])
+	}
+	if err != nil {
+		return []byte("ERROR|" + err.Error() + "|FIM\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("OK")
+	for _, c := range res {
+		if aninhado, ok := c.valor.([]campo); ok {
+			b.WriteString("|" + stringValue(aninhado))
+			continue
+		}
+		b.WriteString("|" + c.chave + "=" + stringValue(c.valor))
+	}
+	b.WriteString("|FIM\n")
+	return []byte(b.String())
+}
+
+// stringValue achata o histórico numa lista de nomes; objetos aninhados viram
+// campos seguintes da mesma linha.
+func stringValue(v any) string {
+	switch v := v.(type) {
+	case []registroOp:
+		nomes := make([]string, len(v))
+		for i, o := range v {
+			nomes[i] = o.Operacao
+		}
+		return strings.Join(nomes, ",")
+	case []campo:
+		pares := make([]string, len(v))
+		for i, c := range v {
+			pares[i] = c.chave + "=" + stringValue(c.valor)
+		}
+		return strings.Join(pares, "|")
+	}
+	return fmt.Sprint(v)
+}
+
+func (s *TestServer) respondJSON(frame []byte, alg *string) ([]byte, error) {
+	doc, _, err := UnwrapJSON(frame, limiteTestServer)
+	if err != nil {
+		return nil, err
+	}
+	var req map[string]any
+	if err := json.Unmarshal(doc, &req); err != nil {
+		return nil, err
+	}
+	confirmar := false
+	if oferta, ok := req[CampoAceitaCompressao].(string); ok && *alg == "" {
+		*alg = Negotiate(oferta, s.Compressoes)
+		confirmar = *alg != ""
+	}
+
+	resp := s.envelopeResponse(req)
+	if confirmar {
+		resp[CampoAceitaCompressao] = *alg
+	}
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if out, err = WrapJSON(out, *alg); err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// respondEnvelope atende msgpack e cbor: os envelopes do JSON em frames com
+// prefixo de tamanho, com a compressão confirmada no cabeçalho estendido.
+func (s *TestServer) respondEnvelope(frame []byte, alg *string) ([]byte, error) {
+	codec := EnvelopeCodecs[s.Protocolo]
+	payload, _, err := ProtoPayload(frame, limiteTestServer)
+	if err != nil {
+		return nil, err
+	}
+	var req map[string]any
+	if err := codec.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	if oferta, ok := req[CampoAceitaCompressao].(string); ok && *alg == "" {
+		*alg = Negotiate(oferta, s.Compressoes)
+	}
+
+	out, err := codec.Marshal(s.envelopeResponse(req))
+	if err != nil {
+		return nil, err
+	}
+	return AppendProtoFrame(nil, out, *alg)
+}
+
+func (s *TestServer) envelopeResponse(req map[string]any) map[string]any {
+	token, _ := req["token"].(string)
+	var resp map[string]any
+	switch req["tipo"] {
+	case "autenticar":
+		aluno, _ := req["aluno_id"].(string)
+		token, res, err := s.auth(aluno)
+		if err != nil {
+			resp = map[string]any{"sucesso": false, "erro": err.Error()}
+			break
+		}
+		dados := jsonObject(res)
+		delete(dados, "token")
+		resp = map[string]any{"sucesso": true, "token": token, "dados_aluno": dados}
+	case "info":
+		res, err := s.execute(token, "info", nil)
+		resp = jsonResult(res, err)
+	case "logout":
+		res, err := s.execute(token, "logout", nil)
+		resp = jsonResult(res, err)
+		if err == nil {
+			resp = map[string]any{"sucesso": true, "mensagem": resp["resultado"].(map[string]any)["mensagem"]}
+		}
+	case "operacao":
+		op, _ := req["operacao"].(string)
+		params := make(map[string]string)
+		if p, ok := req["parametros"].(map[string]any); ok {
+			for k, v := range p {
+				params[k] = jsonParam(v)
+			}
+		}
+		res, err := s.execute(token, op, params)
+		resp = jsonResult(res, err)
+	default:
+		resp = map[string]any{"sucesso": false, "erro": fmt.Sprintf("tipo '%v' desconhecido", req["tipo"])}
+	}
+	resp["timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	return resp
+}
+
+func jsonObject(res []campo) map[string]any {
+	m := make(map[string]any, len(res))
+	for _, c := range res {
+		if aninhado, ok := c.valor.([]campo); ok {
+			m[c.chave] = jsonObject(aninhado)
+			continue
+		}
+		m[c.chave] = c.valor
+	}
+	return m
+}
+
+func jsonResult(res []campo, err error) map[string]any {
+	if err != nil {
+		return map[string]any{"sucesso": false, "erro": err.Error()}
+	}
+	return map[string]any{"sucesso": true, "resultado": jsonObject(res)}
+}
+
+// jsonParam converte um parâmetro JSON para o texto usado por execute; listas
+// viram valores separados por vírgula.
+func jsonParam(v any) string {
+	if l, ok := v.([]any); ok {
+		itens := make([]string, len(l))
+		for i, x := range l {
+			itens[i] = fmt.Sprint(x)
+		}
+		return strings.Join(itens, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+func (s *TestServer) respondProto(frame []byte, alg *string) ([]byte, error) {
+	payload, _, err := ProtoPayload(frame, limiteTestServer)
+	if err != nil {
+		return nil, err
+	}
+	var req pb.Requisicao
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	var res []campo
+	if a := req.GetAuth(); a != nil {
+		_, res, err = s.auth(a.GetAlunoId())
+	} else {
+		op := req.GetOperacao()
+		params := op.GetParametros()
+		if oferta, ok := params[CampoAceitaCompressao]; ok && *alg == "" {
+			*alg = Negotiate(oferta, s.Compressoes)
+		}
+		if op.GetNomeOperacao() == "soma" && params["numeros"] == "" {
+			params["numeros"] = params["nums"]
+		}
+		res, err = s.execute(op.GetToken(), op.GetNomeOperacao(), params)
+	}
+
+	sucesso := err == nil
+	resultado := make(map[string]string, len(res))
+	if err != nil {
+		resultado["erro"] = err.Error()
+	}
+	for _, c := range res {
+		resultado[c.chave] = protoValue(c.valor)
+	}
+	out, err := proto.Marshal(&pb.Resposta{Operacao: &pb.OperacaoResponse{
+		Sucesso:   sucesso,
+		Resultado: resultado,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return AppendProtoFrame(nil, out, *alg)
+}
+
+// protoValue serializa listas e objetos em JSON dentro do mapa de resultado.
+func protoValue(v any) string {
+	switch v := v.(type) {
+	case string, int, float64:
+		return fmt.Sprint(v)
+	case []campo:
+		b, _ := json.Marshal(jsonObject(v))
+		return string(b)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}